@@ -0,0 +1,216 @@
+package algolia
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/algolia/algoliasearch-client-go/algoliasearch"
+)
+
+// AlgoliaAPI is the part of the Algolia API the provider relies on. Resources only go
+// through it, so their logic can run against any implementation, like an in-memory one.
+type AlgoliaAPI interface {
+	// Indices
+	ListIndices() ([]indexInfo, error)
+	CopyIndex(source, destination string) (algoliasearch.UpdateTaskRes, error)
+	MoveIndex(source, destination string) (algoliasearch.UpdateTaskRes, error)
+	DeleteIndex(name string) (algoliasearch.DeleteTaskRes, error)
+	ClearIndex(name string) (algoliasearch.UpdateTaskRes, error)
+	BrowseIndex(name string) (algoliasearch.IndexIterator, error)
+	AddObjects(name string, objects []algoliasearch.Object) (algoliasearch.BatchRes, error)
+
+	// Settings
+	GetSettings(name string) (algoliasearch.Settings, error)
+	GetRawSettings(name string) (map[string]interface{}, error)
+	SetSettings(name string, settings algoliasearch.Map) (algoliasearch.UpdateTaskRes, error)
+
+	// Synonyms
+	SearchSynonyms(name string, page, hitsPerPage int) ([]algoliasearch.Synonym, error)
+	BatchSynonyms(name string, synonyms []algoliasearch.Synonym, replaceExisting bool) (algoliasearch.UpdateTaskRes, error)
+
+	// Rules
+	SearchRules(name string, page, hitsPerPage int) (algoliasearch.SearchRulesRes, error)
+	BatchRules(name string, rules []algoliasearch.Rule, clearExisting bool) (algoliasearch.BatchRulesRes, error)
+
+	// Keys
+	GetAPIKey(key string) (algoliasearch.Key, error)
+	ListAPIKeys() ([]algoliasearch.Key, error)
+
+	// Tasks
+	WaitTask(name string, taskID int) error
+}
+
+// The official client, plus direct REST calls for what it doesn't cover.
+type officialClient struct {
+	client        algoliasearch.Client
+	applicationId string
+	apiKey        string
+	httpClient    *http.Client
+}
+
+func newOfficialClient(applicationId, apiKey string, httpClient *http.Client) *officialClient {
+	client := algoliasearch.NewClient(applicationId, apiKey)
+	client.SetHTTPClient(httpClient)
+
+	return &officialClient{
+		client:        client,
+		applicationId: applicationId,
+		apiKey:        apiKey,
+		httpClient:    httpClient,
+	}
+}
+
+// The client's ListIndexes neither paginates nor exposes primary/replicas, so the
+// list-indices endpoint is queried directly.
+const listIndicesPageSize = 100
+
+type listIndicesRes struct {
+	Items   []indexInfo `json:"items"`
+	NbPages int         `json:"nbPages"`
+}
+
+// Lists every index in the application, following pagination until the last page.
+func (c *officialClient) ListIndices() ([]indexInfo, error) {
+	var indices []indexInfo
+	for page := 0; ; page++ {
+		var res listIndicesRes
+		query := url.Values{
+			"page":        {strconv.Itoa(page)},
+			"hitsPerPage": {strconv.Itoa(listIndicesPageSize)},
+		}
+		if err := c.getJSON("/1/indexes", query, &res); err != nil {
+			return nil, fmt.Errorf("Error listing indices: %v", err)
+		}
+		indices = append(indices, res.Items...)
+		if len(res.Items) == 0 || page+1 >= res.NbPages {
+			return indices, nil
+		}
+	}
+}
+
+func (c *officialClient) CopyIndex(source, destination string) (algoliasearch.UpdateTaskRes, error) {
+	return c.client.CopyIndex(source, destination)
+}
+
+func (c *officialClient) MoveIndex(source, destination string) (algoliasearch.UpdateTaskRes, error) {
+	return c.client.MoveIndex(source, destination)
+}
+
+func (c *officialClient) DeleteIndex(name string) (algoliasearch.DeleteTaskRes, error) {
+	return c.client.InitIndex(name).Delete()
+}
+
+func (c *officialClient) ClearIndex(name string) (algoliasearch.UpdateTaskRes, error) {
+	return c.client.InitIndex(name).Clear()
+}
+
+func (c *officialClient) BrowseIndex(name string) (algoliasearch.IndexIterator, error) {
+	return c.client.InitIndex(name).BrowseAll(algoliasearch.Map{})
+}
+
+func (c *officialClient) AddObjects(name string, objects []algoliasearch.Object) (algoliasearch.BatchRes, error) {
+	return c.client.InitIndex(name).AddObjects(objects)
+}
+
+func (c *officialClient) GetSettings(name string) (algoliasearch.Settings, error) {
+	return c.client.InitIndex(name).GetSettings()
+}
+
+// The client's Settings type drops keys it doesn't know about.
+func (c *officialClient) GetRawSettings(name string) (map[string]interface{}, error) {
+	settings := map[string]interface{}{}
+	err := c.getJSON("/1/indexes/"+url.PathEscape(name)+"/settings", url.Values{}, &settings)
+	return settings, err
+}
+
+func (c *officialClient) SetSettings(name string, settings algoliasearch.Map) (algoliasearch.UpdateTaskRes, error) {
+	return c.client.InitIndex(name).SetSettings(settings)
+}
+
+func (c *officialClient) SearchSynonyms(name string, page, hitsPerPage int) ([]algoliasearch.Synonym, error) {
+	return c.client.InitIndex(name).SearchSynonyms("", nil, page, hitsPerPage)
+}
+
+func (c *officialClient) BatchSynonyms(name string, synonyms []algoliasearch.Synonym, replaceExisting bool) (algoliasearch.UpdateTaskRes, error) {
+	return c.client.InitIndex(name).BatchSynonyms(synonyms, replaceExisting, false)
+}
+
+func (c *officialClient) SearchRules(name string, page, hitsPerPage int) (algoliasearch.SearchRulesRes, error) {
+	return c.client.InitIndex(name).SearchRules(algoliasearch.Map{
+		"query":       "",
+		"page":        page,
+		"hitsPerPage": hitsPerPage,
+	})
+}
+
+func (c *officialClient) BatchRules(name string, rules []algoliasearch.Rule, clearExisting bool) (algoliasearch.BatchRulesRes, error) {
+	return c.client.InitIndex(name).BatchRules(rules, false, clearExisting)
+}
+
+func (c *officialClient) GetAPIKey(key string) (algoliasearch.Key, error) {
+	return c.client.GetAPIKey(key)
+}
+
+func (c *officialClient) ListAPIKeys() ([]algoliasearch.Key, error) {
+	return c.client.ListAPIKeys()
+}
+
+func (c *officialClient) WaitTask(name string, taskID int) error {
+	return c.client.InitIndex(name).WaitTask(taskID)
+}
+
+// Read hosts in the order the official clients try them.
+func (c *officialClient) readHosts() []string {
+	return []string{
+		c.applicationId + "-dsn.algolia.net",
+		c.applicationId + "-1.algolianet.com",
+		c.applicationId + "-2.algolianet.com",
+		c.applicationId + "-3.algolianet.com",
+	}
+}
+
+// Sends a GET request to the Algolia REST API and decodes the JSON response into out,
+// retrying on the next host when one is unreachable.
+func (c *officialClient) getJSON(path string, query url.Values, out interface{}) error {
+	var lastErr error
+	for _, host := range c.readHosts() {
+		u := url.URL{Scheme: "https", Host: host, Path: path, RawQuery: query.Encode()}
+		req, err := http.NewRequest("GET", u.String(), nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("X-Algolia-Application-Id", c.applicationId)
+		req.Header.Set("X-Algolia-API-Key", c.apiKey)
+
+		httpClient := *c.httpClient
+		httpClient.Timeout = 30 * time.Second
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if resp.StatusCode >= 500 {
+			lastErr = fmt.Errorf("%s", body)
+			continue
+		}
+		if resp.StatusCode >= 300 {
+			return fmt.Errorf("%s", body)
+		}
+
+		return json.Unmarshal(body, out)
+	}
+
+	return lastErr
+}
@@ -0,0 +1,249 @@
+package algolia
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/algolia/algoliasearch-client-go/algoliasearch"
+)
+
+const (
+	backupSettingsFile = "settings.ndjson"
+	backupSynonymsFile = "synonyms.ndjson"
+	backupRulesFile    = "rules.ndjson"
+	backupRecordsFile  = "records.ndjson"
+
+	backupPageSize   = 1000
+	restoreBatchSize = 1000
+)
+
+// Writes the settings, synonyms, rules and optionally records of an index as NDJSON files
+// into a new timestamped directory under basePath. Returns the directory that was written,
+// which can later be passed to restore_from.
+func backupIndex(api AlgoliaAPI, name string, basePath string, includeRecords bool) (string, error) {
+	dir := filepath.Join(basePath, name, time.Now().UTC().Format("20060102T150405Z"))
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("Error creating backup directory %s: %v", dir, err)
+	}
+
+	// Raw settings, so those the provider doesn't model are restored as well.
+	settings, err := api.GetRawSettings(name)
+	if err != nil {
+		return "", fmt.Errorf("Error reading settings of index %s for backup: %v", name, err)
+	}
+	if err := writeNDJSON(filepath.Join(dir, backupSettingsFile), func(enc *json.Encoder) error {
+		return enc.Encode(settings)
+	}); err != nil {
+		return "", err
+	}
+
+	if err := writeNDJSON(filepath.Join(dir, backupSynonymsFile), func(enc *json.Encoder) error {
+		for page := 0; ; page++ {
+			synonyms, err := api.SearchSynonyms(name, page, backupPageSize)
+			if err != nil {
+				return fmt.Errorf("Error reading synonyms of index %s for backup: %v", name, err)
+			}
+			for _, synonym := range synonyms {
+				if err := enc.Encode(synonym); err != nil {
+					return err
+				}
+			}
+			if len(synonyms) < backupPageSize {
+				return nil
+			}
+		}
+	}); err != nil {
+		return "", err
+	}
+
+	if err := writeNDJSON(filepath.Join(dir, backupRulesFile), func(enc *json.Encoder) error {
+		for page := 0; ; page++ {
+			res, err := api.SearchRules(name, page, backupPageSize)
+			if err != nil {
+				return fmt.Errorf("Error reading rules of index %s for backup: %v", name, err)
+			}
+			for _, rule := range res.Hits {
+				if err := enc.Encode(rule); err != nil {
+					return err
+				}
+			}
+			if page+1 >= res.NbPages {
+				return nil
+			}
+		}
+	}); err != nil {
+		return "", err
+	}
+
+	if includeRecords {
+		if err := writeNDJSON(filepath.Join(dir, backupRecordsFile), func(enc *json.Encoder) error {
+			it, err := api.BrowseIndex(name)
+			if err != nil {
+				return fmt.Errorf("Error browsing records of index %s for backup: %v", name, err)
+			}
+			for {
+				record, err := it.Next()
+				if err == algoliasearch.NoMoreHitsErr {
+					return nil
+				}
+				if err != nil {
+					return fmt.Errorf("Error browsing records of index %s for backup: %v", name, err)
+				}
+				if err := enc.Encode(record); err != nil {
+					return err
+				}
+			}
+		}); err != nil {
+			return "", err
+		}
+	}
+
+	log.Printf("[INFO] Backed up index %s to %s", name, dir)
+	return dir, nil
+}
+
+// Replays a backup written by backupIndex into the given index. Replicas are left out of the
+// restored settings, as those are managed by the resource configuration.
+func restoreIndex(api AlgoliaAPI, name string, dir string) error {
+	var tasks []int
+
+	var settings []algoliasearch.Map
+	if err := readNDJSON(filepath.Join(dir, backupSettingsFile), func(dec *json.Decoder) error {
+		var s algoliasearch.Map
+		if err := dec.Decode(&s); err != nil {
+			return err
+		}
+		settings = append(settings, s)
+		return nil
+	}); err != nil {
+		return err
+	}
+	for _, s := range settings {
+		delete(s, "replicas")
+		delete(s, "primary")
+		res, err := api.SetSettings(name, s)
+		if err != nil {
+			return fmt.Errorf("Error restoring settings of index %s: %v", name, err)
+		}
+		tasks = append(tasks, res.TaskID)
+	}
+
+	var synonyms []algoliasearch.Synonym
+	if err := readNDJSON(filepath.Join(dir, backupSynonymsFile), func(dec *json.Decoder) error {
+		var synonym algoliasearch.Synonym
+		if err := dec.Decode(&synonym); err != nil {
+			return err
+		}
+		synonyms = append(synonyms, synonym)
+		return nil
+	}); err != nil {
+		return err
+	}
+	if len(synonyms) > 0 {
+		res, err := api.BatchSynonyms(name, synonyms, true)
+		if err != nil {
+			return fmt.Errorf("Error restoring synonyms of index %s: %v", name, err)
+		}
+		tasks = append(tasks, res.TaskID)
+	}
+
+	var rules []algoliasearch.Rule
+	if err := readNDJSON(filepath.Join(dir, backupRulesFile), func(dec *json.Decoder) error {
+		var rule algoliasearch.Rule
+		if err := dec.Decode(&rule); err != nil {
+			return err
+		}
+		rules = append(rules, rule)
+		return nil
+	}); err != nil {
+		return err
+	}
+	if len(rules) > 0 {
+		res, err := api.BatchRules(name, rules, true)
+		if err != nil {
+			return fmt.Errorf("Error restoring rules of index %s: %v", name, err)
+		}
+		tasks = append(tasks, res.TaskID)
+	}
+
+	// Records are optional in a backup, and are sent in batches as they are read.
+	var records []algoliasearch.Object
+	flush := func() error {
+		if len(records) == 0 {
+			return nil
+		}
+		res, err := api.AddObjects(name, records)
+		if err != nil {
+			return fmt.Errorf("Error restoring records of index %s: %v", name, err)
+		}
+		tasks = append(tasks, res.TaskID)
+		records = records[:0]
+		return nil
+	}
+	recordsPath := filepath.Join(dir, backupRecordsFile)
+	if _, err := os.Stat(recordsPath); err == nil {
+		if err := readNDJSON(recordsPath, func(dec *json.Decoder) error {
+			var record algoliasearch.Object
+			if err := dec.Decode(&record); err != nil {
+				return err
+			}
+			records = append(records, record)
+			if len(records) >= restoreBatchSize {
+				return flush()
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+	}
+
+	for _, task := range tasks {
+		if err := api.WaitTask(name, task); err != nil {
+			return fmt.Errorf("Error waiting for restore of index %s: %v", name, err)
+		}
+	}
+
+	log.Printf("[INFO] Restored index %s from %s", name, dir)
+	return nil
+}
+
+func writeNDJSON(path string, write func(enc *json.Encoder) error) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("Error creating backup file %s: %v", path, err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	if err := write(json.NewEncoder(w)); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("Error writing backup file %s: %v", path, err)
+	}
+	return f.Close()
+}
+
+func readNDJSON(path string, read func(dec *json.Decoder) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("Error opening backup file %s: %v", path, err)
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(bufio.NewReader(f))
+	for dec.More() {
+		if err := read(dec); err != nil {
+			return fmt.Errorf("Error reading backup file %s: %v", path, err)
+		}
+	}
+	return nil
+}
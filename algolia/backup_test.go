@@ -0,0 +1,62 @@
+package algolia
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/algolia/algoliasearch-client-go/algoliasearch"
+)
+
+func TestBackupRestore_roundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	api := newFakeAPI()
+	api.addIndex("products", map[string]interface{}{
+		"searchableAttributes": []interface{}{"title", "body"},
+		"hitsPerPage":          50,
+		// Not modelled by the provider, only extra_settings_json can set it.
+		"renderingContent": map[string]interface{}{"facetOrdering": map[string]interface{}{"facets": map[string]interface{}{"order": []interface{}{"brand"}}}},
+	}, 3)
+	api.BatchSynonyms("products", []algoliasearch.Synonym{
+		{ObjectID: "tv", Type: "synonym", Synonyms: []string{"tv", "television"}},
+	}, false)
+	api.BatchRules("products", []algoliasearch.Rule{
+		{ObjectID: "promote-brand", Description: "Promote the brand"},
+	}, false)
+
+	path, err := backupIndex(api, "products", dir, true)
+	if err != nil {
+		t.Fatalf("Error backing up index: %v", err)
+	}
+
+	settings := api.rawSettings("products")
+	synonyms, _ := api.SearchSynonyms("products", 0, 100)
+	rules, _ := api.SearchRules("products", 0, 100)
+	records := api.records("products")
+
+	api.DeleteIndex("products")
+	if err := restoreIndex(api, "products", path); err != nil {
+		t.Fatalf("Error restoring index: %v", err)
+	}
+
+	restoredSynonyms, _ := api.SearchSynonyms("products", 0, 100)
+	restoredRules, _ := api.SearchRules("products", 0, 100)
+	for what, values := range map[string][2]interface{}{
+		"settings": {settings, api.rawSettings("products")},
+		"synonyms": {synonyms, restoredSynonyms},
+		"rules":    {rules.Hits, restoredRules.Hits},
+		"records":  {records, api.records("products")},
+	} {
+		before, _ := json.Marshal(values[0])
+		after, _ := json.Marshal(values[1])
+		if string(before) != string(after) {
+			t.Errorf("Expected %s to be restored as %s, got %s", what, before, after)
+		}
+	}
+}
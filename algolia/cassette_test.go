@@ -0,0 +1,273 @@
+package algolia
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Record and replay of Algolia API interactions, so resources can be tested offline and
+// deterministically. Tests replay the cassettes under testdata/cassettes. Running them with
+// -record, ALGOLIA_APPLICATION_ID and ALGOLIA_API_KEY set records them again against a real
+// application instead, see the README.
+var recordCassettes = flag.Bool("record", false, "record cassettes against the application in ALGOLIA_APPLICATION_ID instead of replaying them")
+
+// A recorded interaction. Hosts and headers aren't kept, key values are redacted the same
+// way as in debug logs, and the application id and api key are replaced with the
+// placeholders replays run with, so cassettes carry no credentials.
+type interaction struct {
+	Method       string `json:"method"`
+	Path         string `json:"path"`
+	Query        string `json:"query,omitempty"`
+	RequestBody  string `json:"request_body,omitempty"`
+	Status       int    `json:"status"`
+	ResponseBody string `json:"response_body"`
+}
+
+type cassetteTransport struct {
+	next   http.RoundTripper
+	path   string
+	replay bool
+	scrub  *strings.Replacer
+
+	mu           sync.Mutex
+	loaded       bool
+	loadErr      error
+	interactions []interaction
+	used         []bool
+}
+
+// scrub lists pairs of credentials and the placeholders they're recorded as.
+func newCassetteTransport(next http.RoundTripper, path string, replay bool, scrub ...string) *cassetteTransport {
+	if replay {
+		return &cassetteTransport{next: next, path: path, replay: true}
+	}
+	return &cassetteTransport{next: next, path: path, loaded: true, scrub: strings.NewReplacer(scrub...)}
+}
+
+func (t *cassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	path := redactPath(req.URL.Path)
+	actual := interaction{
+		Method:      req.Method,
+		Path:        path,
+		Query:       req.URL.Query().Encode(),
+		RequestBody: redactBody(path, body),
+	}
+
+	if t.replay {
+		return t.play(req, actual)
+	}
+	return t.record(req, actual)
+}
+
+func (t *cassetteTransport) record(req *http.Request, i interaction) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	i.Status = resp.StatusCode
+	i.ResponseBody = redactBody(i.Path, body)
+	i.Path = t.scrub.Replace(i.Path)
+	i.Query = t.scrub.Replace(i.Query)
+	i.RequestBody = t.scrub.Replace(i.RequestBody)
+	i.ResponseBody = t.scrub.Replace(i.ResponseBody)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.interactions = append(t.interactions, i)
+
+	// Saved after every interaction, as terraform doesn't give the plugin a chance to
+	// flush anything on exit.
+	b, err := json.MarshalIndent(t.interactions, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(t.path, b, 0600); err != nil {
+		return nil, fmt.Errorf("Error writing cassette %s: %v", t.path, err)
+	}
+	return resp, nil
+}
+
+// Serves the first unused recorded interaction matching the request. Resources run in
+// parallel, so interactions are matched on their content rather than on their order.
+func (t *cassetteTransport) play(req *http.Request, actual interaction) (*http.Response, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.loaded {
+		t.loaded = true
+		b, err := ioutil.ReadFile(t.path)
+		if err == nil {
+			err = json.Unmarshal(b, &t.interactions)
+		}
+		if err != nil {
+			t.loadErr = fmt.Errorf("Error loading cassette %s: %v", t.path, err)
+		}
+		t.used = make([]bool, len(t.interactions))
+	}
+	if t.loadErr != nil {
+		return nil, t.loadErr
+	}
+
+	var closest *interaction
+	for n := range t.interactions {
+		recorded := &t.interactions[n]
+		if t.used[n] || recorded.Method != actual.Method || recorded.Path != actual.Path {
+			continue
+		}
+		if recorded.Query == actual.Query && jsonEqual(recorded.RequestBody, actual.RequestBody) {
+			t.used[n] = true
+			return &http.Response{
+				Status:        fmt.Sprintf("%d %s", recorded.Status, http.StatusText(recorded.Status)),
+				StatusCode:    recorded.Status,
+				Proto:         "HTTP/1.1",
+				ProtoMajor:    1,
+				ProtoMinor:    1,
+				Header:        http.Header{"Content-Type": {"application/json; charset=UTF-8"}},
+				Body:          ioutil.NopCloser(strings.NewReader(recorded.ResponseBody)),
+				ContentLength: int64(len(recorded.ResponseBody)),
+				Request:       req,
+			}, nil
+		}
+		if closest == nil {
+			closest = recorded
+		}
+	}
+
+	if closest == nil {
+		return nil, fmt.Errorf("Cassette %s has no interaction left for %s %s", t.path, actual.Method, actual.Path)
+	}
+	return nil, fmt.Errorf("Cassette %s doesn't match %s %s:\n%s", t.path, actual.Method, actual.Path, interactionDiff(*closest, actual))
+}
+
+// Describes how a request differs from the recorded one. Settings payloads are compared
+// key by key, so a mismatch points at the setting that changed.
+func interactionDiff(expected, actual interaction) string {
+	var b bytes.Buffer
+	if expected.Query != actual.Query {
+		fmt.Fprintf(&b, "  query: expected %q, got %q\n", expected.Query, actual.Query)
+	}
+
+	var e, a map[string]interface{}
+	if json.Unmarshal([]byte(expected.RequestBody), &e) != nil || json.Unmarshal([]byte(actual.RequestBody), &a) != nil {
+		if expected.RequestBody != actual.RequestBody {
+			fmt.Fprintf(&b, "  body: expected %s, got %s\n", expected.RequestBody, actual.RequestBody)
+		}
+		return b.String()
+	}
+
+	keys := map[string]bool{}
+	for k := range e {
+		keys[k] = true
+	}
+	for k := range a {
+		keys[k] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	for _, k := range sorted {
+		ev, inExpected := e[k]
+		av, inActual := a[k]
+		switch {
+		case !inActual:
+			fmt.Fprintf(&b, "- %s: %s\n", k, toJSON(ev))
+		case !inExpected:
+			fmt.Fprintf(&b, "+ %s: %s\n", k, toJSON(av))
+		case !reflect.DeepEqual(ev, av):
+			fmt.Fprintf(&b, "~ %s: expected %s, got %s\n", k, toJSON(ev), toJSON(av))
+		}
+	}
+	return b.String()
+}
+
+func jsonEqual(a, b string) bool {
+	if a == b {
+		return true
+	}
+	var av, bv interface{}
+	if json.Unmarshal([]byte(a), &av) != nil || json.Unmarshal([]byte(b), &bv) != nil {
+		return false
+	}
+	return reflect.DeepEqual(av, bv)
+}
+
+func toJSON(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
+
+// Credentials that show up in recorded requests or responses end up as the placeholders
+// replays run with.
+func TestCassetteTransport_recordScrubsCredentials(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cassette")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "cassette.json")
+
+	const applicationId, apiKey = "REALAPP123", "0123456789abcdef0123456789abcdef"
+	transport := newCassetteTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+			Body: ioutil.NopCloser(strings.NewReader(`{"value":"` + apiKey + `","description":"for ` + applicationId +
+				`","hosts":["realapp123-dsn.algolia.net"],"message":"key ` + apiKey + `"}`)),
+		}, nil
+	}), path, false, apiKey, "test-api-key", applicationId, "TESTAPP", strings.ToLower(applicationId), "testapp")
+
+	req, _ := http.NewRequest("GET", "https://"+applicationId+"-dsn.algolia.net/1/keys/"+apiKey+"?from="+applicationId, nil)
+	req.Header.Set("X-Algolia-API-Key", apiKey)
+	req.Header.Set("X-Algolia-Application-Id", applicationId)
+	if _, err := transport.RoundTrip(req); err != nil {
+		t.Fatalf("Error recording: %v", err)
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Error reading cassette: %v", err)
+	}
+	for _, secret := range []string{apiKey, applicationId, strings.ToLower(applicationId)} {
+		if strings.Contains(string(b), secret) {
+			t.Fatalf("Expected %s to be scrubbed from the cassette, got:\n%s", secret, b)
+		}
+	}
+	if !strings.Contains(string(b), "testapp-dsn.algolia.net") || !strings.Contains(string(b), "from=TESTAPP") {
+		t.Fatalf("Expected credentials to be replaced with placeholders, got:\n%s", b)
+	}
+}
@@ -1,16 +1,170 @@
 package algolia
 
 import (
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
 	"github.com/algolia/algoliasearch-client-go/algoliasearch"
 )
 
 type Config struct {
 	ApplicationId string
 	ApiKey        string
+	IndexPrefix   string
+	IndexSuffix   string
+	ReadOnly      bool
+
+	MaxConcurrentRequests int
+	RequestsPerSecond     float64
+	ListIndicesCacheTTL   time.Duration
+
+	AllowedIndexPatterns []string
+	ProtectedIndices     []string
+
+	// Transport the calls to Algolia go through, http.DefaultTransport when nil. Tests set
+	// it to replay recorded interactions.
+	transport http.RoundTripper
+}
+
+// AlgoliaClient is the provider meta handed to every resource and data source.
+type AlgoliaClient struct {
+	api         AlgoliaAPI
+	indexPrefix string
+	indexSuffix string
+	indexCache  *indexListCache
+
+	allowedIndexPatterns []string
+	protectedIndices     []string
+}
+
+func (c *Config) Client() *AlgoliaClient {
+	transport := c.transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	httpClient := &http.Client{
+		Transport: newLoggingTransport(
+			newThrottledTransport(
+				newTracingTransport(transport),
+				c.MaxConcurrentRequests,
+				c.RequestsPerSecond,
+			),
+		),
+	}
+
+	var api AlgoliaAPI = newOfficialClient(c.ApplicationId, c.ApiKey, httpClient)
+	if c.ReadOnly {
+		api = readOnlyAPI{AlgoliaAPI: api}
+	}
+
+	return &AlgoliaClient{
+		api:         api,
+		indexPrefix: c.IndexPrefix,
+		indexSuffix: c.IndexSuffix,
+		indexCache:  &indexListCache{ttl: c.ListIndicesCacheTTL},
+
+		allowedIndexPatterns: c.AllowedIndexPatterns,
+		protectedIndices:     c.ProtectedIndices,
+	}
+}
+
+// Returns the name of the index in Algolia for a name used in configuration.
+func (c *AlgoliaClient) indexName(name string) string {
+	if name == "" {
+		return name
+	}
+	return c.indexPrefix + name + c.indexSuffix
+}
+
+// Returns the name used in configuration for an index in Algolia. Names that don't carry
+// the prefix and suffix are returned as is.
+func (c *AlgoliaClient) logicalIndexName(name string) string {
+	if !strings.HasPrefix(name, c.indexPrefix) || !strings.HasSuffix(name, c.indexSuffix) {
+		return name
+	}
+	if len(name) < len(c.indexPrefix)+len(c.indexSuffix) {
+		return name
+	}
+	return name[len(c.indexPrefix) : len(name)-len(c.indexSuffix)]
+}
+
+func (c *AlgoliaClient) logicalIndexNames(names []string) []string {
+	if names == nil {
+		return nil
+	}
+	logical := make([]string, len(names))
+	for i, name := range names {
+		logical[i] = c.logicalIndexName(unwrapVirtualReplica(name))
+		if name != unwrapVirtualReplica(name) {
+			logical[i] = "virtual(" + logical[i] + ")"
+		}
+	}
+	return logical
+}
+
+// Guards against pointing a workspace at the wrong index: changes are only allowed on
+// indices matching allowed_index_patterns, when any are configured.
+func (c *AlgoliaClient) checkIndexAllowed(name string) error {
+	if len(c.allowedIndexPatterns) == 0 || matchesAny(c.allowedIndexPatterns, name) {
+		return nil
+	}
+	return fmt.Errorf("Index %s does not match any of the allowed_index_patterns %v", name, c.allowedIndexPatterns)
 }
 
-func (c *Config) Client() *algoliasearch.Client {
-	client := algoliasearch.NewClient(c.ApplicationId, c.ApiKey)
+// Protected indices may never be deleted or have their settings reset.
+func (c *AlgoliaClient) checkIndexNotProtected(name string, action string) error {
+	if matchesAny(c.protectedIndices, name) {
+		return fmt.Errorf("Refusing to %s index %s: it matches protected_indices %v", action, name, c.protectedIndices)
+	}
+	return nil
+}
+
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		// Patterns are checked in providerConfigure, so errors can't happen here.
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Applies the index prefix and suffix to the replicas of a settings payload. Attaching an
+// index as a replica changes it, so each replica goes through the same guardrails as the
+// index itself.
+func (c *AlgoliaClient) qualifyReplicas(payload algoliasearch.Map) (algoliasearch.Map, error) {
+	replicas, ok := payload["replicas"].([]string)
+	if !ok {
+		return payload, nil
+	}
+
+	qualified := make([]string, len(replicas))
+	for i, name := range replicas {
+		fullName := c.indexName(unwrapVirtualReplica(name))
+		if err := c.checkIndexAllowed(fullName); err != nil {
+			return nil, err
+		}
+		if err := c.checkIndexNotProtected(fullName, "attach as a replica"); err != nil {
+			return nil, err
+		}
+
+		qualified[i] = fullName
+		if name != unwrapVirtualReplica(name) {
+			qualified[i] = "virtual(" + qualified[i] + ")"
+		}
+	}
+	payload["replicas"] = qualified
+	return payload, nil
+}
 
-	return &client
+// Replicas may be declared as virtual(name).
+func unwrapVirtualReplica(name string) string {
+	if strings.HasPrefix(name, "virtual(") && strings.HasSuffix(name, ")") {
+		return name[len("virtual(") : len(name)-1]
+	}
+	return name
 }
@@ -0,0 +1,176 @@
+package algolia
+
+import (
+	"fmt"
+
+	"github.com/algolia/algoliasearch-client-go/algoliasearch"
+)
+
+// Looks up the ACL of the configured key. The admin key isn't a regular key and can't be
+// looked up, but it's the only one allowed to list keys, so that's used to recognize it.
+func lookupKeyACL(api AlgoliaAPI, apiKey string) (acl []string, admin bool, err error) {
+	key, err := api.GetAPIKey(apiKey)
+	if err == nil {
+		return key.ACL, false, nil
+	}
+
+	if _, listErr := api.ListAPIKeys(); listErr == nil {
+		return nil, true, nil
+	}
+	return nil, false, err
+}
+
+// Makes sure the application id and api key work, and returns the ACL of the key. The
+// admin key can do anything, so it has no ACL to check calls against.
+func validateCredentials(api AlgoliaAPI, config Config) (acl []string, admin bool, err error) {
+	acl, admin, err = lookupKeyACL(api, config.ApiKey)
+	if err != nil {
+		return nil, false, fmt.Errorf("Error validating Algolia credentials for application %s: %v. Check application_id and api_key, or set skip_credentials_validation = true", config.ApplicationId, err)
+	}
+	return acl, admin, nil
+}
+
+// Runs the checks of the key at configure time. skip_credentials_validation only skips
+// validating it, a read_only provider is still warned about a key that can write.
+func checkCredentials(client *AlgoliaClient, config Config, skipValidation bool) error {
+	if !skipValidation {
+		acl, admin, err := validateCredentials(client.api, config)
+		if err != nil {
+			return err
+		}
+		if !admin {
+			client.api = restrictToKeyACL(client.api, config.ApplicationId, acl)
+		}
+	}
+	if config.ReadOnly {
+		checkReadOnlyKey(client.api, config.ApiKey)
+	}
+	return nil
+}
+
+// Checks every call against the ACL of the key. Which ACLs are needed depends on what the
+// provider is asked to do, a configuration with only data sources doesn't need to write, so
+// calls are checked as they are made instead of all at once. read_only refuses mutating
+// calls before they get here, with a clearer error than a missing ACL.
+func restrictToKeyACL(api AlgoliaAPI, applicationId string, acl []string) AlgoliaAPI {
+	if ro, ok := api.(readOnlyAPI); ok {
+		return readOnlyAPI{AlgoliaAPI: restrictToKeyACL(ro.AlgoliaAPI, applicationId, acl)}
+	}
+
+	granted := make(map[string]bool, len(acl))
+	for _, a := range acl {
+		granted[a] = true
+	}
+	return keyACLAPI{AlgoliaAPI: api, applicationId: applicationId, granted: granted}
+}
+
+type keyACLAPI struct {
+	AlgoliaAPI
+	applicationId string
+	granted       map[string]bool
+}
+
+func (a keyACLAPI) require(acl string, operation string) error {
+	if a.granted[acl] {
+		return nil
+	}
+	return fmt.Errorf("The api_key for application %s is missing the %s ACL needed to %s. Add it to the key, or set skip_credentials_validation = true", a.applicationId, acl, operation)
+}
+
+func (a keyACLAPI) ListIndices() ([]indexInfo, error) {
+	if err := a.require("listIndexes", "list indices"); err != nil {
+		return nil, err
+	}
+	return a.AlgoliaAPI.ListIndices()
+}
+
+func (a keyACLAPI) CopyIndex(source, destination string) (res algoliasearch.UpdateTaskRes, err error) {
+	if err := a.require("addObject", fmt.Sprintf("copy index %s to %s", source, destination)); err != nil {
+		return res, err
+	}
+	return a.AlgoliaAPI.CopyIndex(source, destination)
+}
+
+func (a keyACLAPI) MoveIndex(source, destination string) (res algoliasearch.UpdateTaskRes, err error) {
+	if err := a.require("addObject", fmt.Sprintf("move index %s to %s", source, destination)); err != nil {
+		return res, err
+	}
+	return a.AlgoliaAPI.MoveIndex(source, destination)
+}
+
+func (a keyACLAPI) DeleteIndex(name string) (res algoliasearch.DeleteTaskRes, err error) {
+	if err := a.require("deleteIndex", "delete index "+name); err != nil {
+		return res, err
+	}
+	return a.AlgoliaAPI.DeleteIndex(name)
+}
+
+func (a keyACLAPI) ClearIndex(name string) (res algoliasearch.UpdateTaskRes, err error) {
+	if err := a.require("deleteIndex", "clear index "+name); err != nil {
+		return res, err
+	}
+	return a.AlgoliaAPI.ClearIndex(name)
+}
+
+func (a keyACLAPI) BrowseIndex(name string) (algoliasearch.IndexIterator, error) {
+	if err := a.require("browse", "browse records of index "+name); err != nil {
+		return nil, err
+	}
+	return a.AlgoliaAPI.BrowseIndex(name)
+}
+
+func (a keyACLAPI) AddObjects(name string, objects []algoliasearch.Object) (res algoliasearch.BatchRes, err error) {
+	if err := a.require("addObject", "save records to index "+name); err != nil {
+		return res, err
+	}
+	return a.AlgoliaAPI.AddObjects(name, objects)
+}
+
+func (a keyACLAPI) GetSettings(name string) (res algoliasearch.Settings, err error) {
+	if err := a.require("settings", "read settings of index "+name); err != nil {
+		return res, err
+	}
+	return a.AlgoliaAPI.GetSettings(name)
+}
+
+func (a keyACLAPI) GetRawSettings(name string) (map[string]interface{}, error) {
+	if err := a.require("settings", "read settings of index "+name); err != nil {
+		return nil, err
+	}
+	return a.AlgoliaAPI.GetRawSettings(name)
+}
+
+func (a keyACLAPI) SetSettings(name string, settings algoliasearch.Map) (res algoliasearch.UpdateTaskRes, err error) {
+	if err := a.require("editSettings", "set settings of index "+name); err != nil {
+		return res, err
+	}
+	return a.AlgoliaAPI.SetSettings(name, settings)
+}
+
+func (a keyACLAPI) SearchSynonyms(name string, page, hitsPerPage int) ([]algoliasearch.Synonym, error) {
+	if err := a.require("settings", "read synonyms of index "+name); err != nil {
+		return nil, err
+	}
+	return a.AlgoliaAPI.SearchSynonyms(name, page, hitsPerPage)
+}
+
+func (a keyACLAPI) BatchSynonyms(name string, synonyms []algoliasearch.Synonym, replaceExisting bool) (res algoliasearch.UpdateTaskRes, err error) {
+	if err := a.require("editSettings", "save synonyms to index "+name); err != nil {
+		return res, err
+	}
+	return a.AlgoliaAPI.BatchSynonyms(name, synonyms, replaceExisting)
+}
+
+func (a keyACLAPI) SearchRules(name string, page, hitsPerPage int) (res algoliasearch.SearchRulesRes, err error) {
+	if err := a.require("settings", "read rules of index "+name); err != nil {
+		return res, err
+	}
+	return a.AlgoliaAPI.SearchRules(name, page, hitsPerPage)
+}
+
+func (a keyACLAPI) BatchRules(name string, rules []algoliasearch.Rule, clearExisting bool) (res algoliasearch.BatchRulesRes, err error) {
+	if err := a.require("editSettings", "save rules to index "+name); err != nil {
+		return res, err
+	}
+	return a.AlgoliaAPI.BatchRules(name, rules, clearExisting)
+}
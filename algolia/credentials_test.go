@@ -0,0 +1,70 @@
+package algolia
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/algolia/algoliasearch-client-go/algoliasearch"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func TestCheckCredentials_keyACL(t *testing.T) {
+	searchKey := []string{"search", "listIndexes", "settings"}
+	writeKey := []string{"search", "listIndexes", "settings", "editSettings", "addObject", "deleteIndex"}
+
+	cases := []struct {
+		name      string
+		acl       []string
+		admin     bool
+		skip      bool
+		createErr string
+	}{
+		{name: "search key", acl: searchKey, createErr: "missing the editSettings ACL needed to set settings of index products"},
+		{name: "write key", acl: writeKey},
+		{name: "admin key", admin: true},
+		{name: "search key, validation skipped", acl: searchKey, skip: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			meta, api := newFakeClient()
+			api.admin = c.admin
+			if !c.admin {
+				api.keys["key"] = algoliasearch.Key{Value: "key", ACL: c.acl}
+			}
+			api.addIndex("existing", map[string]interface{}{"searchableAttributes": []interface{}{"title"}}, 10)
+
+			if err := checkCredentials(meta, Config{ApplicationId: "TESTAPP", ApiKey: "key"}, c.skip); err != nil {
+				t.Fatalf("Error checking credentials: %v", err)
+			}
+
+			// Data sources only read, which every key here can do.
+			d := schema.TestResourceDataRaw(t, dataSourceIndex().Schema, map[string]interface{}{"name": "existing"})
+			if err := dataSourceIndex().Read(d, meta); err != nil {
+				t.Fatalf("Error reading data source: %v", err)
+			}
+
+			_, err := testResourceApply(t, resourceIndex(), nil, map[string]interface{}{"name": "products"}, meta)
+			if c.createErr == "" {
+				if err != nil {
+					t.Fatalf("Error creating index: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), c.createErr) {
+				t.Fatalf("Expected error containing %q, got %v", c.createErr, err)
+			}
+			if api.hasIndex("products") {
+				t.Fatal("Expected index products not to be created")
+			}
+		})
+	}
+}
+
+func TestCheckCredentials_invalidKey(t *testing.T) {
+	meta, _ := newFakeClient()
+	err := checkCredentials(meta, Config{ApplicationId: "TESTAPP", ApiKey: "unknown"}, false)
+	if err == nil || !strings.Contains(err.Error(), "Error validating Algolia credentials for application TESTAPP") {
+		t.Fatalf("Expected a validation error, got %v", err)
+	}
+}
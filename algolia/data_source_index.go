@@ -0,0 +1,127 @@
+package algolia
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func dataSourceIndex() *schema.Resource {
+	exclude := append([]string{"extra_settings_json", "settings_ownership", "managed_settings", "settings_fingerprint", "drift_report", "reindex_changes"}, indexLifecycleAttributes...)
+	s := dataSourceSchemaFromResourceSchema(resourceIndex().Schema, exclude)
+	s["name"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Required:    true,
+		Description: "The name of the index to look up",
+	}
+
+	// Stats from the list-indices API
+	s["entries"] = &schema.Schema{
+		Type:        schema.TypeInt,
+		Computed:    true,
+		Description: "Number of records in the index.",
+	}
+	s["data_size"] = &schema.Schema{
+		Type:        schema.TypeInt,
+		Computed:    true,
+		Description: "Number of bytes of the index in minified format.",
+	}
+	s["file_size"] = &schema.Schema{
+		Type:        schema.TypeInt,
+		Computed:    true,
+		Description: "Number of bytes of the index binary file.",
+	}
+	s["last_build_time_s"] = &schema.Schema{
+		Type:        schema.TypeInt,
+		Computed:    true,
+		Description: "Last build time in seconds.",
+	}
+	s["number_of_pending_tasks"] = &schema.Schema{
+		Type:        schema.TypeInt,
+		Computed:    true,
+		Description: "Number of pending indexing operations.",
+	}
+	s["primary"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "Name of the primary index, if this index is a replica.",
+	}
+	s["created_at"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "Index creation date.",
+	}
+	s["updated_at"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "Date of last update.",
+	}
+
+	return &schema.Resource{
+		Read:   dataSourceIndexRead,
+		Schema: s,
+	}
+}
+
+func dataSourceIndexRead(d *schema.ResourceData, m interface{}) error {
+	meta := m.(*AlgoliaClient)
+	name := meta.indexName(d.Get("name").(string))
+
+	info, err := meta.findIndex(name)
+	if err != nil {
+		return fmt.Errorf("Error reading index %s: %v", name, err)
+	}
+	if info == nil {
+		return fmt.Errorf("Index %s does not exist", name)
+	}
+
+	settings, err := meta.api.GetSettings(name)
+	if err != nil {
+		return fmt.Errorf("Error reading settings of index %s: %v", name, err)
+	}
+
+	d.SetId(name)
+	d.Set("full_name", name)
+	settings.Replicas = meta.logicalIndexNames(settings.Replicas)
+	readResourceFromSettings(d, settings)
+	setIndexInfo(d, info)
+	d.Set("primary", meta.logicalIndexName(info.Primary))
+	if info.Replicas != nil {
+		d.Set("replicas", meta.logicalIndexNames(info.Replicas))
+	}
+	return nil
+}
+
+func setIndexInfo(d *schema.ResourceData, info *indexInfo) {
+	d.Set("entries", info.Entries)
+	d.Set("data_size", info.DataSize)
+	d.Set("file_size", info.FileSize)
+	d.Set("last_build_time_s", info.LastBuildTimeS)
+	d.Set("number_of_pending_tasks", info.NumberOfPendingTasks)
+	d.Set("primary", info.Primary)
+	d.Set("created_at", info.CreatedAt)
+	d.Set("updated_at", info.UpdatedAt)
+}
+
+// Turns a resource schema into its read-only data source equivalent, dropping the
+// given attributes that only make sense on a managed resource.
+func dataSourceSchemaFromResourceSchema(rs map[string]*schema.Schema, exclude []string) map[string]*schema.Schema {
+	excluded := make(map[string]bool, len(exclude))
+	for _, k := range exclude {
+		excluded[k] = true
+	}
+
+	ds := make(map[string]*schema.Schema, len(rs))
+	for k, v := range rs {
+		if excluded[k] {
+			continue
+		}
+		ds[k] = &schema.Schema{
+			Type:        v.Type,
+			Elem:        v.Elem,
+			Computed:    true,
+			Description: v.Description,
+		}
+	}
+	return ds
+}
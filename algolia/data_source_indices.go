@@ -0,0 +1,150 @@
+package algolia
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/hashcode"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func dataSourceIndices() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceIndicesRead,
+
+		Schema: map[string]*schema.Schema{
+			"name_prefix": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return indices whose name, without the provider index_prefix and index_suffix, starts with this prefix.",
+			},
+			"name_regex": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "Only return indices whose name, without the provider index_prefix and index_suffix, matches this regular expression.",
+				ValidateFunc: StringIsValidRegexp(),
+			},
+			"names": &schema.Schema{
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Computed:    true,
+				Description: "Names of the matching indices, without the provider index_prefix and index_suffix, as used in configuration.",
+			},
+			"indices": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Matching indices along with their stats.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"entries": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"data_size": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"file_size": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"last_build_time_s": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"number_of_pending_tasks": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"primary": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"replicas": &schema.Schema{
+							Type:     schema.TypeList,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+							Computed: true,
+						},
+						"created_at": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"updated_at": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceIndicesRead(d *schema.ResourceData, m interface{}) error {
+	meta := m.(*AlgoliaClient)
+	prefix := d.Get("name_prefix").(string)
+
+	// ValidateFunc is skipped for values only known at apply time, so the regex can still
+	// be invalid here.
+	var re *regexp.Regexp
+	if v, ok := d.GetOk("name_regex"); ok {
+		var err error
+		re, err = regexp.Compile(v.(string))
+		if err != nil {
+			return fmt.Errorf("Invalid name_regex %q: %v", v, err)
+		}
+	}
+
+	all, err := meta.cachedIndices()
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(all))
+	indices := make([]map[string]interface{}, 0, len(all))
+	for _, info := range all {
+		// Names are returned as used in configuration, so indices outside of the provider
+		// index_prefix and index_suffix can't be referred to and are left out.
+		name := meta.logicalIndexName(info.Name)
+		if meta.indexName(name) != info.Name || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		if re != nil && !re.MatchString(name) {
+			continue
+		}
+
+		primary := info.Primary
+		if primary != "" {
+			primary = meta.logicalIndexName(primary)
+		}
+
+		names = append(names, name)
+		indices = append(indices, map[string]interface{}{
+			"name":                    name,
+			"entries":                 info.Entries,
+			"data_size":               info.DataSize,
+			"file_size":               info.FileSize,
+			"last_build_time_s":       info.LastBuildTimeS,
+			"number_of_pending_tasks": info.NumberOfPendingTasks,
+			"primary":                 primary,
+			"replicas":                meta.logicalIndexNames(info.Replicas),
+			"created_at":              info.CreatedAt,
+			"updated_at":              info.UpdatedAt,
+		})
+	}
+
+	d.SetId(strconv.Itoa(hashcode.String(strings.Join(names, ","))))
+	if err := d.Set("names", names); err != nil {
+		return fmt.Errorf("Error setting names: %v", err)
+	}
+	if err := d.Set("indices", indices); err != nil {
+		return fmt.Errorf("Error setting indices: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package algolia
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func TestDataSourceIndices_read(t *testing.T) {
+	meta, api := newFakeClient()
+	meta.indexPrefix = "staging_"
+	api.addIndex("staging_products", nil, 2)
+	api.addIndex("staging_products_by_price", nil, 0)
+	api.addIndex("staging_users", nil, 0)
+	api.addIndex("prod_products", nil, 0)
+
+	d := schema.TestResourceDataRaw(t, dataSourceIndices().Schema, map[string]interface{}{
+		"name_prefix": "products",
+		"name_regex":  "_by_",
+	})
+	if err := dataSourceIndicesRead(d, meta); err != nil {
+		t.Fatalf("Error reading indices: %v", err)
+	}
+	if names := d.Get("names").([]interface{}); !reflect.DeepEqual(names, []interface{}{"products_by_price"}) {
+		t.Fatalf("Expected only products_by_price, got %v", names)
+	}
+}
+
+func TestDataSourceIndices_invalidRegex(t *testing.T) {
+	meta, _ := newFakeClient()
+	d := schema.TestResourceDataRaw(t, dataSourceIndices().Schema, map[string]interface{}{
+		"name_regex": "products(",
+	})
+
+	err := dataSourceIndicesRead(d, meta)
+	if err == nil || !strings.Contains(err.Error(), `Invalid name_regex "products("`) {
+		t.Fatalf("Expected an invalid name_regex to fail, got: %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package algolia
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/hashcode"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// Secured API keys are derived locally from a parent key, so this data source never
+// talks to Algolia.
+func dataSourceSecuredAPIKey() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceSecuredAPIKeyRead,
+
+		Schema: map[string]*schema.Schema{
+			"parent_key": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Sensitive:   true,
+				Description: "Search API key the secured key is derived from.",
+			},
+			"filters": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Filters applied to every search made with the secured key.",
+			},
+			"valid_until": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Description:  "Unix timestamp after which the secured key expires.",
+				ValidateFunc: IntGTE(0),
+			},
+			"restrict_indices": &schema.Schema{
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Optional:    true,
+				Description: "List of indices the secured key is allowed to query, index_prefix and index_suffix are applied.",
+			},
+			"restrict_sources": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "IPv4 network allowed to use the secured key.",
+			},
+			"user_token": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "User identifier used for rate limiting and analytics.",
+			},
+			"key": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "The derived secured API key.",
+			},
+		},
+	}
+}
+
+func dataSourceSecuredAPIKeyRead(d *schema.ResourceData, m interface{}) error {
+	params := url.Values{}
+	if v, ok := d.GetOk("filters"); ok {
+		params.Set("filters", v.(string))
+	}
+	if v, ok := d.GetOk("valid_until"); ok {
+		params.Set("validUntil", strconv.Itoa(v.(int)))
+	}
+	if v, ok := d.GetOk("restrict_indices"); ok {
+		var indices []string
+		for _, name := range castStringList(v.([]interface{})) {
+			indices = append(indices, m.(*AlgoliaClient).indexName(name))
+		}
+		params.Set("restrictIndices", strings.Join(indices, ","))
+	}
+	if v, ok := d.GetOk("restrict_sources"); ok {
+		params.Set("restrictSources", v.(string))
+	}
+	if v, ok := d.GetOk("user_token"); ok {
+		params.Set("userToken", v.(string))
+	}
+
+	query := params.Encode()
+	d.SetId(strconv.Itoa(hashcode.String(query)))
+	d.Set("key", generateSecuredAPIKey(d.Get("parent_key").(string), query))
+	return nil
+}
+
+// Same derivation as the official clients: base64(hex(HMAC-SHA256(parentKey, query)) + query)
+func generateSecuredAPIKey(parentKey string, query string) string {
+	h := hmac.New(sha256.New, []byte(parentKey))
+	h.Write([]byte(query))
+	return base64.StdEncoding.EncodeToString([]byte(hex.EncodeToString(h.Sum(nil)) + query))
+}
@@ -0,0 +1,72 @@
+package algolia
+
+import (
+	"encoding/base64"
+	"net/url"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// Expected keys were generated with GenerateSecuredAPIKey from the official Go client.
+func TestDataSourceSecuredAPIKey_knownAnswers(t *testing.T) {
+	cases := []struct {
+		raw      map[string]interface{}
+		expected string
+	}{
+		{
+			raw: map[string]interface{}{
+				"parent_key": "parent-search-key",
+				"filters":    "_tags:public",
+			},
+			expected: "NmI2YmU3YjY4OThkNGRkZGVmYTJlN2MzMDNjNGM0NTZmN2YzYTA0MTY2ZWE0ZTQyNDg3MDVkODY1MWUzYzk3MWZpbHRlcnM9X3RhZ3MlM0FwdWJsaWM=",
+		},
+		{
+			raw: map[string]interface{}{
+				"parent_key":       "parent-search-key",
+				"filters":          "user:42 AND visible:true",
+				"valid_until":      2000000000,
+				"restrict_sources": "192.168.1.0/24",
+				"user_token":       "user-42",
+			},
+			expected: "ZWFmY2RjZDUxOTA5NjI1ZjA3NzVmY2I0OWYyZmIwYzlmMzg0NTE5YWNmMmI2MmM0NDVlNDFiNTQwODk1OWE3MmZpbHRlcnM9dXNlciUzQTQyK0FORCt2aXNpYmxlJTNBdHJ1ZSZyZXN0cmljdFNvdXJjZXM9MTkyLjE2OC4xLjAlMkYyNCZ1c2VyVG9rZW49dXNlci00MiZ2YWxpZFVudGlsPTIwMDAwMDAwMDA=",
+		},
+	}
+
+	for _, c := range cases {
+		meta, _ := newFakeClient()
+		d := schema.TestResourceDataRaw(t, dataSourceSecuredAPIKey().Schema, c.raw)
+		if err := dataSourceSecuredAPIKeyRead(d, meta); err != nil {
+			t.Fatalf("Error reading secured API key: %v", err)
+		}
+		if got := d.Get("key").(string); got != c.expected {
+			t.Errorf("Expected key %s, got %s", c.expected, got)
+		}
+	}
+}
+
+func TestDataSourceSecuredAPIKey_restrictIndices(t *testing.T) {
+	meta, _ := newFakeClient()
+	meta.indexPrefix = "staging_"
+	meta.indexSuffix = "_v2"
+	d := schema.TestResourceDataRaw(t, dataSourceSecuredAPIKey().Schema, map[string]interface{}{
+		"parent_key":       "parent-search-key",
+		"restrict_indices": []interface{}{"products", "users"},
+	})
+	if err := dataSourceSecuredAPIKeyRead(d, meta); err != nil {
+		t.Fatalf("Error reading secured API key: %v", err)
+	}
+
+	// The key is the hex HMAC followed by the query it signs.
+	decoded, err := base64.StdEncoding.DecodeString(d.Get("key").(string))
+	if err != nil {
+		t.Fatalf("Error decoding key: %v", err)
+	}
+	params, err := url.ParseQuery(string(decoded[64:]))
+	if err != nil {
+		t.Fatalf("Error parsing key query: %v", err)
+	}
+	if got := params.Get("restrictIndices"); got != "staging_products_v2,staging_users_v2" {
+		t.Fatalf("Expected restrictIndices to carry index_prefix and index_suffix, got %q", got)
+	}
+}
@@ -0,0 +1,105 @@
+package algolia
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var invalidIdentifierChars = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// Export writes an algolia_index block to w, and the terraform import command adopting it
+// to imports, for every index of the application whose name starts with namePrefix, so
+// existing applications can be brought under terraform. The output targets the Terraform
+// 0.11 configuration language the provider is built against, which has no import blocks.
+// Settings are read with the same mapping as the resource, and those left at their default
+// are omitted. Synonyms, rules and API keys aren't managed by the provider yet, so they
+// aren't exported. The index_prefix and index_suffix of meta are stripped from names.
+func Export(w io.Writer, imports io.Writer, meta *AlgoliaClient, namePrefix string) error {
+	indices, err := meta.api.ListIndices()
+	if err != nil {
+		return err
+	}
+	sort.Slice(indices, func(i, j int) bool { return indices[i].Name < indices[j].Name })
+
+	s := resourceIndex().Schema
+	used := map[string]bool{}
+	for _, info := range indices {
+		name := meta.logicalIndexName(info.Name)
+		if meta.indexName(name) != info.Name || !strings.HasPrefix(name, namePrefix) {
+			continue
+		}
+
+		settings, err := meta.api.GetSettings(info.Name)
+		if err != nil {
+			return fmt.Errorf("Error reading settings of index %s: %v", info.Name, err)
+		}
+		settings.Replicas = meta.logicalIndexNames(settings.Replicas)
+		values := settingsAttributeValues(settings)
+
+		attrs := make([]string, 0, len(values))
+		for attr, v := range values {
+			if !isDefaultSetting(s, attr, v) {
+				attrs = append(attrs, attr)
+			}
+		}
+		sort.Strings(attrs)
+
+		id := resourceIdentifier(name, used)
+		var b bytes.Buffer
+		fmt.Fprintf(&b, "resource \"algolia_index\" %q {\n", id)
+		fmt.Fprintf(&b, "  name = %s\n", hclValue(name))
+		for _, attr := range attrs {
+			fmt.Fprintf(&b, "  %s = %s\n", attr, hclValue(values[attr]))
+		}
+		fmt.Fprintf(&b, "}\n\n")
+
+		if _, err := w.Write(b.Bytes()); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(imports, "terraform import algolia_index.%s %s\n", id, shellQuote(name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Turns an index name into a unique resource name.
+func resourceIdentifier(name string, used map[string]bool) string {
+	id := invalidIdentifierChars.ReplaceAllString(name, "_")
+	if id == "" || !(id[0] == '_' || (id[0] >= 'a' && id[0] <= 'z') || (id[0] >= 'A' && id[0] <= 'Z')) {
+		id = "index_" + id
+	}
+
+	unique := id
+	for n := 2; used[unique]; n++ {
+		unique = fmt.Sprintf("%s_%d", id, n)
+	}
+	used[unique] = true
+	return unique
+}
+
+func hclValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		// Escape interpolation sequences, which Terraform 0.11 would otherwise evaluate.
+		return strings.Replace(strconv.Quote(v), "${", "$${", -1)
+	case []string:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = hclValue(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// Quotes s as a single shell word.
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
@@ -0,0 +1,105 @@
+package algolia
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"testing"
+)
+
+func TestHclValue(t *testing.T) {
+	cases := []struct {
+		value    interface{}
+		expected string
+	}{
+		{"title", `"title"`},
+		{"${var.name}", `"$${var.name}"`},
+		// Template directives only exist from Terraform 0.12.
+		{"%{if}", `"%{if}"`},
+		{"say \"hi\"", `"say \"hi\""`},
+		{[]string{"title", "${body}"}, `["title", "$${body}"]`},
+		{20, "20"},
+		{true, "true"},
+	}
+	for _, c := range cases {
+		if got := hclValue(c.value); got != c.expected {
+			t.Errorf("hclValue(%#v) = %s, expected %s", c.value, got, c.expected)
+		}
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	if got := shellQuote("it's"); got != `'it'\''s'` {
+		t.Fatalf("Expected the quote to be escaped, got %s", got)
+	}
+}
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files under testdata instead of comparing against them")
+
+func testGolden(t *testing.T, path string, got []byte) {
+	t.Helper()
+	if *updateGolden {
+		if err := ioutil.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Error reading %s: %v", path, err)
+	}
+	if !bytes.Equal(got, expected) {
+		t.Fatalf("Output doesn't match %s, run with -update if the change is expected:\n%s", path, got)
+	}
+}
+
+// Settings as Algolia returns them for an index, with every setting the provider knows
+// about at its default unless overridden.
+func testIndexSettings(overrides map[string]interface{}) map[string]interface{} {
+	settings := map[string]interface{}{}
+	for attr, s := range resourceIndex().Schema {
+		if key, ok := settingsAttributeKeys[attr]; ok && s.Default != nil {
+			settings[key] = s.Default
+		}
+	}
+	for k, v := range overrides {
+		settings[k] = v
+	}
+	return settings
+}
+
+func TestExport(t *testing.T) {
+	meta, api := newFakeClient()
+	meta.indexPrefix = "staging_"
+	meta.indexSuffix = "_v2"
+
+	api.addIndex("staging_products_v2", testIndexSettings(map[string]interface{}{
+		"searchableAttributes": []interface{}{"title", "unordered(description)"},
+		"customRanking":        []interface{}{"desc(popularity)"},
+		"replicas":             []interface{}{"staging_products_price_asc_v2"},
+	}), 10)
+	api.addIndex("staging_products_price_asc_v2", testIndexSettings(map[string]interface{}{
+		"primary": "staging_products_v2",
+		"ranking": []interface{}{"asc(price)", "typo", "geo", "words", "filters", "proximity", "attribute", "exact", "custom"},
+	}), 10)
+	api.addIndex("staging_2019-archive_v2", testIndexSettings(map[string]interface{}{
+		"hitsPerPage": 50,
+	}), 0)
+	// Outside of the prefix and suffix, or of the filter, so not exported.
+	api.addIndex("prod_products_v2", nil, 0)
+	api.addIndex("staging_products", nil, 0)
+	api.addIndex("staging_users_v2", nil, 0)
+
+	var config, imports bytes.Buffer
+	if err := Export(&config, &imports, meta, "products"); err != nil {
+		t.Fatalf("Error exporting: %v", err)
+	}
+	testGolden(t, "testdata/export/products.tf", config.Bytes())
+	testGolden(t, "testdata/export/products_imports.sh", imports.Bytes())
+
+	config.Reset()
+	imports.Reset()
+	if err := Export(&config, &imports, meta, "2019"); err != nil {
+		t.Fatalf("Error exporting: %v", err)
+	}
+	testGolden(t, "testdata/export/archive.tf", config.Bytes())
+}
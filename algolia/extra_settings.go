@@ -0,0 +1,131 @@
+package algolia
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/algolia/algoliasearch-client-go/algoliasearch"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// Parses extra_settings_json into a map. An empty string means no extra settings.
+func parseExtraSettings(v string) (map[string]interface{}, error) {
+	extra := map[string]interface{}{}
+	if strings.TrimSpace(v) == "" {
+		return extra, nil
+	}
+	if err := json.Unmarshal([]byte(v), &extra); err != nil {
+		return nil, err
+	}
+	return extra, nil
+}
+
+// Extra settings must be a JSON object that doesn't set anything the schema already models,
+// otherwise the two would fight over the same key.
+func validateExtraSettingsJSON(i interface{}, k string) (s []string, es []error) {
+	v, ok := i.(string)
+	if !ok {
+		es = append(es, fmt.Errorf("expected type of %s to be string", k))
+		return
+	}
+
+	extra, err := parseExtraSettings(v)
+	if err != nil {
+		es = append(es, fmt.Errorf("expected %s to be a JSON object, got %v", k, err))
+		return
+	}
+
+	modelled := map[string]string{}
+	for attr, key := range settingsAttributeKeys {
+		modelled[key] = attr
+	}
+	for _, key := range sortedKeys(extra) {
+		if attr, ok := modelled[key]; ok {
+			es = append(es, fmt.Errorf("%s sets %q, which is managed by the %s attribute", k, key, attr))
+		}
+	}
+	return
+}
+
+func suppressEquivalentJSON(k, old, new string, d *schema.ResourceData) bool {
+	o, err := parseExtraSettings(old)
+	if err != nil {
+		return false
+	}
+	n, err := parseExtraSettings(new)
+	if err != nil {
+		return false
+	}
+	return reflect.DeepEqual(o, n)
+}
+
+// Merges the extra settings into the payload built by settingsAsMap.
+func mergeExtraSettings(m algoliasearch.Map, extra map[string]interface{}) algoliasearch.Map {
+	for k, v := range extra {
+		m[k] = v
+	}
+	return m
+}
+
+// Extra settings to send on update: the new value of every key in config, and null for
+// keys that were removed from config so Algolia resets them to their default.
+func changedExtraSettings(d *schema.ResourceData) (map[string]interface{}, error) {
+	o, n := d.GetChange("extra_settings_json")
+	old, err := parseExtraSettings(o.(string))
+	if err != nil {
+		return nil, err
+	}
+	extra, err := parseExtraSettings(n.(string))
+	if err != nil {
+		return nil, err
+	}
+
+	for k := range old {
+		if _, ok := extra[k]; !ok {
+			extra[k] = nil
+		}
+	}
+	return extra, nil
+}
+
+// Settings returned by the client only hold modelled keys, so the raw settings are used
+// to refresh extra settings. Only keys already in state are kept, as the API also
+// returns defaults for many settings that were never configured.
+func readExtraSettings(d *schema.ResourceData, meta *AlgoliaClient, name string) error {
+	current, err := parseExtraSettings(d.Get("extra_settings_json").(string))
+	if err != nil || len(current) == 0 {
+		return nil
+	}
+
+	live, err := meta.api.GetRawSettings(name)
+	if err != nil {
+		return fmt.Errorf("Error reading settings of index %s: %v", name, err)
+	}
+
+	extra := map[string]interface{}{}
+	for k := range current {
+		if v, ok := live[k]; ok && v != nil {
+			extra[k] = v
+		}
+	}
+
+	// encoding/json sorts map keys, so the result is stable between refreshes.
+	b, err := json.Marshal(extra)
+	if err != nil {
+		return err
+	}
+	d.Set("extra_settings_json", string(b))
+	return nil
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,408 @@
+package algolia
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/algolia/algoliasearch-client-go/algoliasearch"
+)
+
+// In-memory AlgoliaAPI, so resource logic can be tested without an application. Tasks
+// complete immediately, and like Algolia, changing the settings of a missing index
+// creates it.
+type fakeAPI struct {
+	mu      sync.Mutex
+	indices map[string]*fakeIndex
+	keys    map[string]algoliasearch.Key
+	taskID  int
+	clock   time.Time
+
+	// Whether the key in use is the admin key, the only one allowed to list keys.
+	admin bool
+
+	// Settings payloads sent through SetSettings, by index, in order.
+	payloads map[string][]algoliasearch.Map
+}
+
+type fakeIndex struct {
+	settings  map[string]interface{}
+	records   []algoliasearch.Object
+	synonyms  []algoliasearch.Synonym
+	rules     []algoliasearch.Rule
+	pending   int
+	createdAt string
+	updatedAt string
+}
+
+func newFakeAPI() *fakeAPI {
+	return &fakeAPI{
+		indices:  map[string]*fakeIndex{},
+		keys:     map[string]algoliasearch.Key{},
+		clock:    time.Date(2018, 2, 6, 12, 0, 0, 0, time.UTC),
+		payloads: map[string][]algoliasearch.Map{},
+	}
+}
+
+// Returns a provider meta going through the fake, without any prefix, suffix or guardrail.
+func newFakeClient() (*AlgoliaClient, *fakeAPI) {
+	api := newFakeAPI()
+	return &AlgoliaClient{api: api, indexCache: &indexListCache{}}, api
+}
+
+// Adds an index as if it had been created outside of terraform.
+func (f *fakeAPI) addIndex(name string, settings map[string]interface{}, records int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	index := f.index(name)
+	for k, v := range settings {
+		index.settings[k] = v
+	}
+	for i := 0; i < records; i++ {
+		index.records = append(index.records, algoliasearch.Object{"objectID": fmt.Sprintf("%d", i)})
+	}
+}
+
+// Leaves tasks pending on the index, as if they were still being processed.
+func (f *fakeAPI) setPendingTasks(name string, pending int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.index(name).pending = pending
+}
+
+func (f *fakeAPI) records(name string) []algoliasearch.Object {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if index, ok := f.indices[name]; ok {
+		return append([]algoliasearch.Object(nil), index.records...)
+	}
+	return nil
+}
+
+func (f *fakeAPI) hasIndex(name string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, ok := f.indices[name]
+	return ok
+}
+
+func (f *fakeAPI) rawSettings(name string) map[string]interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if index, ok := f.indices[name]; ok {
+		return copySettings(index.settings)
+	}
+	return nil
+}
+
+func (f *fakeAPI) lastPayload(name string) algoliasearch.Map {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	payloads := f.payloads[name]
+	if len(payloads) == 0 {
+		return nil
+	}
+	return payloads[len(payloads)-1]
+}
+
+// Returns the index, creating it when missing. Callers hold the lock.
+func (f *fakeAPI) index(name string) *fakeIndex {
+	index, ok := f.indices[name]
+	if !ok {
+		now := f.tick()
+		index = &fakeIndex{settings: map[string]interface{}{}, createdAt: now, updatedAt: now}
+		f.indices[name] = index
+	}
+	return index
+}
+
+func (f *fakeAPI) tick() string {
+	f.clock = f.clock.Add(time.Second)
+	return f.clock.Format(time.RFC3339)
+}
+
+func (f *fakeAPI) task() int {
+	f.taskID++
+	return f.taskID
+}
+
+func (f *fakeAPI) updated(index *fakeIndex) algoliasearch.UpdateTaskRes {
+	index.updatedAt = f.tick()
+	return algoliasearch.UpdateTaskRes{TaskID: f.task(), UpdatedAt: index.updatedAt}
+}
+
+func (f *fakeAPI) notFound(name string) error {
+	return fmt.Errorf("{\"message\":\"Index %s does not exist\",\"status\":404}\n", name)
+}
+
+func copySettings(settings map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(settings))
+	for k, v := range settings {
+		c[k] = v
+	}
+	return c
+}
+
+func (f *fakeAPI) ListIndices() ([]indexInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	names := make([]string, 0, len(f.indices))
+	for name := range f.indices {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Like the official client, an application without indices lists as nil.
+	var indices []indexInfo
+	for _, name := range names {
+		index := f.indices[name]
+		info := indexInfo{
+			Name:      name,
+			CreatedAt: index.createdAt,
+			UpdatedAt: index.updatedAt,
+			Entries:   len(index.records),
+
+			NumberOfPendingTasks: index.pending,
+			PendingTask:          index.pending > 0,
+		}
+		if primary, ok := index.settings["primary"].(string); ok {
+			info.Primary = primary
+		}
+		switch replicas := index.settings["replicas"].(type) {
+		case []string:
+			info.Replicas = replicas
+		case []interface{}:
+			info.Replicas = castStringList(replicas)
+		}
+		indices = append(indices, info)
+	}
+	return indices, nil
+}
+
+func (f *fakeAPI) CopyIndex(source, destination string) (algoliasearch.UpdateTaskRes, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	src, ok := f.indices[source]
+	if !ok {
+		return algoliasearch.UpdateTaskRes{}, f.notFound(source)
+	}
+
+	// Like Algolia, replicas aren't copied.
+	settings := copySettings(src.settings)
+	delete(settings, "replicas")
+	now := f.tick()
+	f.indices[destination] = &fakeIndex{
+		settings:  settings,
+		records:   append([]algoliasearch.Object(nil), src.records...),
+		synonyms:  append([]algoliasearch.Synonym(nil), src.synonyms...),
+		rules:     append([]algoliasearch.Rule(nil), src.rules...),
+		createdAt: now,
+		updatedAt: now,
+	}
+	return algoliasearch.UpdateTaskRes{TaskID: f.task(), UpdatedAt: now}, nil
+}
+
+func (f *fakeAPI) MoveIndex(source, destination string) (algoliasearch.UpdateTaskRes, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	src, ok := f.indices[source]
+	if !ok {
+		return algoliasearch.UpdateTaskRes{}, f.notFound(source)
+	}
+	if _, ok := src.settings["replicas"]; ok {
+		return algoliasearch.UpdateTaskRes{}, fmt.Errorf("{\"message\":\"Cannot move an index with replicas\",\"status\":400}\n")
+	}
+
+	delete(f.indices, source)
+	f.indices[destination] = src
+	return f.updated(src), nil
+}
+
+func (f *fakeAPI) DeleteIndex(name string) (algoliasearch.DeleteTaskRes, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.indices, name)
+	return algoliasearch.DeleteTaskRes{TaskID: f.task(), DeletedAt: f.tick()}, nil
+}
+
+func (f *fakeAPI) ClearIndex(name string) (algoliasearch.UpdateTaskRes, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	index, ok := f.indices[name]
+	if !ok {
+		return algoliasearch.UpdateTaskRes{}, f.notFound(name)
+	}
+	index.records = nil
+	return f.updated(index), nil
+}
+
+type fakeIterator struct {
+	records []algoliasearch.Object
+}
+
+func (it *fakeIterator) Next() (algoliasearch.Map, error) {
+	if len(it.records) == 0 {
+		return nil, algoliasearch.NoMoreHitsErr
+	}
+	record := it.records[0]
+	it.records = it.records[1:]
+	return algoliasearch.Map(record), nil
+}
+
+func (f *fakeAPI) BrowseIndex(name string) (algoliasearch.IndexIterator, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	index, ok := f.indices[name]
+	if !ok {
+		return nil, f.notFound(name)
+	}
+	return &fakeIterator{records: append([]algoliasearch.Object(nil), index.records...)}, nil
+}
+
+func (f *fakeAPI) AddObjects(name string, objects []algoliasearch.Object) (algoliasearch.BatchRes, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	index := f.index(name)
+	index.records = append(index.records, objects...)
+	res := f.updated(index)
+	return algoliasearch.BatchRes{TaskID: res.TaskID}, nil
+}
+
+func (f *fakeAPI) GetSettings(name string) (algoliasearch.Settings, error) {
+	var settings algoliasearch.Settings
+	raw, err := f.GetRawSettings(name)
+	if err != nil {
+		return settings, err
+	}
+
+	// Decoded the way the client does, through the JSON representation.
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return settings, err
+	}
+	err = json.Unmarshal(b, &settings)
+	return settings, err
+}
+
+func (f *fakeAPI) GetRawSettings(name string) (map[string]interface{}, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	index, ok := f.indices[name]
+	if !ok {
+		return nil, f.notFound(name)
+	}
+	return copySettings(index.settings), nil
+}
+
+func (f *fakeAPI) SetSettings(name string, settings algoliasearch.Map) (algoliasearch.UpdateTaskRes, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.payloads[name] = append(f.payloads[name], settings)
+
+	index := f.index(name)
+	for k, v := range settings {
+		// null resets a setting to its default.
+		if v == nil {
+			delete(index.settings, k)
+			continue
+		}
+		index.settings[k] = v
+	}
+	return f.updated(index), nil
+}
+
+func (f *fakeAPI) SearchSynonyms(name string, page, hitsPerPage int) ([]algoliasearch.Synonym, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	index, ok := f.indices[name]
+	if !ok {
+		return nil, f.notFound(name)
+	}
+	return pageOf(len(index.synonyms), page, hitsPerPage, func(from, to int) interface{} {
+		return append([]algoliasearch.Synonym(nil), index.synonyms[from:to]...)
+	}).([]algoliasearch.Synonym), nil
+}
+
+func (f *fakeAPI) BatchSynonyms(name string, synonyms []algoliasearch.Synonym, replaceExisting bool) (algoliasearch.UpdateTaskRes, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	index := f.index(name)
+	if replaceExisting {
+		index.synonyms = nil
+	}
+	index.synonyms = append(index.synonyms, synonyms...)
+	return f.updated(index), nil
+}
+
+func (f *fakeAPI) SearchRules(name string, page, hitsPerPage int) (algoliasearch.SearchRulesRes, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	index, ok := f.indices[name]
+	if !ok {
+		return algoliasearch.SearchRulesRes{}, f.notFound(name)
+	}
+	hits := pageOf(len(index.rules), page, hitsPerPage, func(from, to int) interface{} {
+		return append([]algoliasearch.Rule(nil), index.rules[from:to]...)
+	}).([]algoliasearch.Rule)
+	return algoliasearch.SearchRulesRes{
+		Hits:    hits,
+		NbHits:  len(index.rules),
+		Page:    page,
+		NbPages: (len(index.rules) + hitsPerPage - 1) / hitsPerPage,
+	}, nil
+}
+
+func (f *fakeAPI) BatchRules(name string, rules []algoliasearch.Rule, clearExisting bool) (algoliasearch.BatchRulesRes, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	index := f.index(name)
+	if clearExisting {
+		index.rules = nil
+	}
+	index.rules = append(index.rules, rules...)
+	res := f.updated(index)
+	return algoliasearch.BatchRulesRes{TaskID: res.TaskID, UpdatedAt: res.UpdatedAt}, nil
+}
+
+func (f *fakeAPI) GetAPIKey(key string) (algoliasearch.Key, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	k, ok := f.keys[key]
+	if !ok {
+		return k, fmt.Errorf("{\"message\":\"Key does not exist\",\"status\":404}\n")
+	}
+	return k, nil
+}
+
+func (f *fakeAPI) ListAPIKeys() ([]algoliasearch.Key, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.admin {
+		return nil, fmt.Errorf("{\"message\":\"Method not allowed with this API key\",\"status\":403}\n")
+	}
+	keys := make([]algoliasearch.Key, 0, len(f.keys))
+	for _, k := range f.keys {
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+func (f *fakeAPI) WaitTask(name string, taskID int) error {
+	return nil
+}
+
+// Slices the items of the given page, clamped to the number of items.
+func pageOf(n, page, hitsPerPage int, slice func(from, to int) interface{}) interface{} {
+	from := page * hitsPerPage
+	if from > n {
+		from = n
+	}
+	to := from + hitsPerPage
+	if to > n {
+		to = n
+	}
+	return slice(from, to)
+}
@@ -0,0 +1,108 @@
+package algolia
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/algolia/algoliasearch-client-go/algoliasearch"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// The settings terraform is responsible for, in the shape they're sent to Algolia: every
+// setting in authoritative mode, only the managed ones in additive mode, plus extra settings.
+func managedSettingsPayload(d *schema.ResourceData) algoliasearch.Map {
+	settings := buildSettingsFromResourceData(d)
+	payload := settingsAsMap(settings)
+	if d.Get("settings_ownership").(string) == "additive" {
+		payload = partialSettingsAsMap(settings, castStringList(d.Get("managed_settings").([]interface{})))
+	}
+
+	// Invalid JSON is caught by validation, there's nothing to add if it slips through.
+	extra, _ := parseExtraSettings(d.Get("extra_settings_json").(string))
+	return mergeExtraSettings(payload, extra)
+}
+
+// Canonical hash of a settings payload. encoding/json sorts map keys, so equal settings
+// always hash the same.
+func settingsFingerprint(payload algoliasearch.Map) string {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// Keys whose value differs between two settings payloads, sorted.
+func changedSettingsKeys(old, new algoliasearch.Map) []string {
+	keys := map[string]bool{}
+	for k, v := range old {
+		if !reflect.DeepEqual(v, new[k]) {
+			keys[k] = true
+		}
+	}
+	for k, v := range new {
+		if !reflect.DeepEqual(v, old[k]) {
+			keys[k] = true
+		}
+	}
+
+	changed := make([]string, 0, len(keys))
+	for k := range keys {
+		changed = append(changed, k)
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// Compares the settings read from Algolia with what terraform last applied or read, and
+// reports what changed since in drift_report, so drift can be attributed to a change made
+// outside of terraform rather than to a previous apply. The report accumulates until the
+// next apply, as the refresh after the one that found the drift compares against it.
+func reportSettingsDrift(d *schema.ResourceData, name string, before algoliasearch.Map, updatedAt string) {
+	after := managedSettingsPayload(d)
+	fingerprint := settingsFingerprint(after)
+
+	reported := castStringList(d.Get("drift_report").([]interface{}))
+	previous := d.Get("settings_fingerprint").(string)
+	if previous != "" && previous != fingerprint {
+		changed := changedSettingsKeys(before, after)
+		log.Printf("[WARN] Settings of index %s changed outside Terraform at %s: %s",
+			name, updatedAt, strings.Join(changed, ", "))
+		reported = mergeSortedKeys(reported, changed)
+	}
+	// Set even when empty, as an empty computed list missing from state shows up as
+	// computed in every plan.
+	d.Set("drift_report", reported)
+
+	d.Set("settings_fingerprint", fingerprint)
+	d.Set("updated_at", updatedAt)
+}
+
+// Records the settings terraform just wrote as the ones drift is compared against.
+func recordAppliedSettings(d *schema.ResourceData) {
+	d.Set("settings_fingerprint", settingsFingerprint(managedSettingsPayload(d)))
+	d.Set("drift_report", []string{})
+	d.Set("reindex_changes", []string{})
+}
+
+func mergeSortedKeys(a, b []string) []string {
+	keys := map[string]bool{}
+	for _, k := range a {
+		keys[k] = true
+	}
+	for _, k := range b {
+		keys[k] = true
+	}
+	merged := make([]string, 0, len(keys))
+	for k := range keys {
+		merged = append(merged, k)
+	}
+	sort.Strings(merged)
+	return merged
+}
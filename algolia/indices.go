@@ -0,0 +1,75 @@
+package algolia
+
+import (
+	"sync"
+	"time"
+)
+
+type indexInfo struct {
+	Name                 string   `json:"name"`
+	CreatedAt            string   `json:"createdAt"`
+	UpdatedAt            string   `json:"updatedAt"`
+	Entries              int      `json:"entries"`
+	DataSize             int      `json:"dataSize"`
+	FileSize             int      `json:"fileSize"`
+	LastBuildTimeS       int      `json:"lastBuildTimeS"`
+	NumberOfPendingTasks int      `json:"numberOfPendingTasks"`
+	PendingTask          bool     `json:"pendingTask"`
+	Primary              string   `json:"primary"`
+	Replicas             []string `json:"replicas"`
+}
+
+// Listing indices is a single call for the whole application, so the result is shared by
+// every resource and data source instead of looking up indices one by one.
+type indexListCache struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	fetchedAt time.Time
+	indices   []indexInfo
+
+	// Whether indices holds a listing at all. An application without indices lists as
+	// empty, which is as cacheable as any other listing.
+	loaded bool
+}
+
+// Returns the cached index list, listing indices again once the cache has expired.
+func (c *AlgoliaClient) cachedIndices() ([]indexInfo, error) {
+	cache := c.indexCache
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if cache.loaded && time.Since(cache.fetchedAt) < cache.ttl {
+		return cache.indices, nil
+	}
+
+	indices, err := c.api.ListIndices()
+	if err != nil {
+		return nil, err
+	}
+	cache.indices = indices
+	cache.loaded = true
+	cache.fetchedAt = time.Now()
+	return indices, nil
+}
+
+// Drops the cached index list, for after the provider itself created or deleted an index.
+func (c *AlgoliaClient) invalidateIndexCache() {
+	c.indexCache.mu.Lock()
+	defer c.indexCache.mu.Unlock()
+	c.indexCache.indices = nil
+	c.indexCache.loaded = false
+}
+
+// Looks up a single index in the cached index list. Returns nil if the index doesn't exist.
+func (c *AlgoliaClient) findIndex(name string) (*indexInfo, error) {
+	indices, err := c.cachedIndices()
+	if err != nil {
+		return nil, err
+	}
+	for i := range indices {
+		if indices[i].Name == name {
+			return &indices[i], nil
+		}
+	}
+	return nil, nil
+}
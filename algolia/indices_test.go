@@ -0,0 +1,56 @@
+package algolia
+
+import (
+	"testing"
+	"time"
+)
+
+// Counts the list-indices calls that reach the API.
+type countingListAPI struct {
+	AlgoliaAPI
+	calls int
+}
+
+func (a *countingListAPI) ListIndices() ([]indexInfo, error) {
+	a.calls++
+	return a.AlgoliaAPI.ListIndices()
+}
+
+func TestCachedIndices(t *testing.T) {
+	cases := []struct {
+		name    string
+		indices []string
+	}{
+		{name: "empty application"},
+		{name: "with indices", indices: []string{"products", "users"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			meta, fake := newFakeClient()
+			for _, name := range c.indices {
+				fake.addIndex(name, nil, 0)
+			}
+			api := &countingListAPI{AlgoliaAPI: fake}
+			meta.api = api
+			meta.indexCache.ttl = time.Minute
+
+			for i := 0; i < 3; i++ {
+				if _, err := meta.findIndex("products"); err != nil {
+					t.Fatalf("Error finding index: %v", err)
+				}
+			}
+			if api.calls != 1 {
+				t.Fatalf("Expected indices to be listed once, got %d calls", api.calls)
+			}
+
+			meta.invalidateIndexCache()
+			if _, err := meta.findIndex("products"); err != nil {
+				t.Fatalf("Error finding index: %v", err)
+			}
+			if api.calls != 2 {
+				t.Fatalf("Expected indices to be listed again after invalidating, got %d calls", api.calls)
+			}
+		})
+	}
+}
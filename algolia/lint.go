@@ -0,0 +1,267 @@
+package algolia
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+var (
+	rankingPattern            = regexp.MustCompile(`^(typo|geo|words|filters|proximity|attribute|exact|custom|(asc|desc)\(.+\))$`)
+	customRankingPattern      = regexp.MustCompile(`^(asc|desc)\(.+\)$`)
+	attributeToSnippetPattern = regexp.MustCompile(`^[^:]+(:[0-9]+)?$`)
+	searchableModifierPattern = regexp.MustCompile(`^(unordered|ordered)\((.+)\)$`)
+)
+
+// Validators for the elements of list settings. They only run when linting: the resource
+// never validated these lists, and configurations relying on that must keep planning.
+var lintElemValidators = map[string]schema.SchemaValidateFunc{
+	"custom_ranking":        StringMatches(customRankingPattern, "asc(attribute) or desc(attribute)"),
+	"ranking":               StringMatches(rankingPattern, "a ranking criterion, asc(attribute) or desc(attribute)"),
+	"attributes_to_snippet": StringMatches(attributeToSnippetPattern, "attribute or attribute:words"),
+}
+
+// LintError is a problem found in a settings JSON file, at a 1-based line and column.
+type LintError struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e LintError) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Message)
+}
+
+// LintSettingsFile checks a settings JSON file, as returned by the Algolia get settings
+// API, against the same types and ranges as algolia_index, plus grammars and cross-field
+// rules that are only linted, so existing configurations keep planning. Keys the provider
+// doesn't model are left alone, they can go in extra_settings_json.
+func LintSettingsFile(path string) ([]LintError, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	errorAt := func(offset int64, format string, args ...interface{}) LintError {
+		line, column := position(data, offset)
+		return LintError{File: path, Line: line, Column: column, Message: fmt.Sprintf(format, args...)}
+	}
+	syntaxError := func(err error) []LintError {
+		if serr, ok := err.(*json.SyntaxError); ok {
+			return []LintError{errorAt(serr.Offset, "%v", serr)}
+		}
+		return []LintError{errorAt(int64(len(data)), "%v", err)}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if tok, err := dec.Token(); err != nil {
+		return syntaxError(err), nil
+	} else if tok != json.Delim('{') {
+		return []LintError{errorAt(dec.InputOffset(), "expected settings to be a JSON object")}, nil
+	}
+
+	attributes := map[string]string{}
+	for attr, key := range settingsAttributeKeys {
+		attributes[key] = attr
+	}
+	s := resourceIndex().Schema
+
+	var lintErrors []LintError
+	values := map[string]interface{}{}
+	offsets := map[string]int64{}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return syntaxError(err), nil
+		}
+		key := tok.(string)
+		offset := dec.InputOffset()
+
+		var raw interface{}
+		if err := dec.Decode(&raw); err != nil {
+			return syntaxError(err), nil
+		}
+
+		attr, ok := attributes[key]
+		if !ok {
+			continue
+		}
+		offsets[attr] = offset
+
+		v, err := lintValue(s[attr], key, raw)
+		if err != nil {
+			lintErrors = append(lintErrors, errorAt(offset, "%v", err))
+			continue
+		}
+		values[attr] = v
+
+		for _, err := range validateValue(s[attr], attr, key, v) {
+			lintErrors = append(lintErrors, errorAt(offset, "%v", err))
+		}
+	}
+
+	// Settings missing from the file are at their default, like in a plan.
+	get := func(attr string) interface{} {
+		if v, ok := values[attr]; ok {
+			return v
+		}
+		return s[attr].Default
+	}
+	for _, err := range settingsCrossFieldErrors(get) {
+		var offset int64
+		if rerr, ok := err.(*settingsRuleError); ok {
+			offset = offsets[rerr.attribute]
+		}
+		lintErrors = append(lintErrors, errorAt(offset, "%v", err))
+	}
+
+	return lintErrors, nil
+}
+
+// Converts a JSON value into the type the schema stores it as.
+func lintValue(s *schema.Schema, key string, raw interface{}) (interface{}, error) {
+	switch s.Type {
+	case schema.TypeList:
+		items, ok := raw.([]interface{})
+		if !ok && raw != nil {
+			return nil, fmt.Errorf("expected %s to be a list of strings", key)
+		}
+		for _, item := range items {
+			if _, ok := item.(string); !ok {
+				return nil, fmt.Errorf("expected %s to be a list of strings", key)
+			}
+		}
+		return items, nil
+	case schema.TypeInt:
+		n, ok := raw.(json.Number)
+		if !ok {
+			return nil, fmt.Errorf("expected %s to be an integer", key)
+		}
+		i, err := strconv.Atoi(n.String())
+		if err != nil {
+			return nil, fmt.Errorf("expected %s to be an integer, got %s", key, n)
+		}
+		return i, nil
+	case schema.TypeBool:
+		b, ok := raw.(bool)
+		if !ok {
+			return nil, fmt.Errorf("expected %s to be a boolean", key)
+		}
+		return b, nil
+	default:
+		// typoTolerance is either a boolean or a string in the API, the schema only has strings.
+		if b, ok := raw.(bool); ok {
+			return strconv.FormatBool(b), nil
+		}
+		if raw == nil {
+			return "", nil
+		}
+		str, ok := raw.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected %s to be a string", key)
+		}
+		return str, nil
+	}
+}
+
+// Runs the schema validator of an attribute, and the lint validator of its elements for lists.
+func validateValue(s *schema.Schema, attr string, key string, v interface{}) []error {
+	var errs []error
+	if s.ValidateFunc != nil {
+		_, es := s.ValidateFunc(v, key)
+		errs = append(errs, es...)
+	}
+
+	if validate, ok := lintElemValidators[attr]; ok && s.Type == schema.TypeList {
+		for i, item := range v.([]interface{}) {
+			_, es := validate(item, fmt.Sprintf("%s[%d]", key, i))
+			errs = append(errs, es...)
+		}
+	}
+	return errs
+}
+
+// Turns a byte offset into a 1-based line and column.
+func position(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	line, column := 1, 1
+	for _, c := range data[:offset] {
+		if c == '\n' {
+			line++
+			column = 1
+		} else {
+			column++
+		}
+	}
+	return line, column
+}
+
+// A broken rule between settings, reported on the attribute that has to change.
+type settingsRuleError struct {
+	attribute string
+	message   string
+}
+
+func (e *settingsRuleError) Error() string {
+	return e.message
+}
+
+// Rules that involve more than one setting, with values read through get.
+func settingsCrossFieldErrors(get func(attr string) interface{}) []error {
+	var errs []error
+
+	oneTypo, _ := get("min_word_size_for_1_typo").(int)
+	twoTypos, _ := get("min_word_size_for_2_typos").(int)
+	if oneTypo > twoTypos {
+		errs = append(errs, &settingsRuleError{
+			attribute: "min_word_size_for_1_typo",
+			message:   fmt.Sprintf("min_word_size_for_1_typo (%d) can't be greater than min_word_size_for_2_typos (%d)", oneTypo, twoTypos),
+		})
+	}
+
+	// Searchable attributes may be wrapped in a modifier, or list several attributes
+	// with the same priority separated by commas.
+	searchable := map[string]bool{}
+	for _, entry := range toStringList(get("searchable_attributes")) {
+		if match := searchableModifierPattern.FindStringSubmatch(entry); match != nil {
+			entry = match[2]
+		}
+		for _, attr := range strings.Split(entry, ",") {
+			searchable[strings.TrimSpace(attr)] = true
+		}
+	}
+	if len(searchable) > 0 {
+		for _, attr := range toStringList(get("disable_typo_tolerance_on_attributes")) {
+			if !searchable[attr] {
+				errs = append(errs, &settingsRuleError{
+					attribute: "disable_typo_tolerance_on_attributes",
+					message:   fmt.Sprintf("disable_typo_tolerance_on_attributes contains %q, which isn't in searchable_attributes", attr),
+				})
+			}
+		}
+	}
+
+	return errs
+}
+
+// Same as castStringList, but also accepts lists that are already []string.
+func toStringList(v interface{}) []string {
+	switch v := v.(type) {
+	case []string:
+		return v
+	case []interface{}:
+		return castStringList(v)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,59 @@
+package algolia
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLintSettingsFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "settings.json")
+	settings := `{
+  "customRanking": ["popularity"],
+  "minWordSizefor1Typo": 9,
+  "minWordSizefor2Typos": 8
+}`
+	if err := ioutil.WriteFile(path, []byte(settings), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	lintErrors, err := LintSettingsFile(path)
+	if err != nil {
+		t.Fatalf("Error linting %s: %v", path, err)
+	}
+	var messages []string
+	for _, e := range lintErrors {
+		messages = append(messages, e.Error())
+	}
+	got := strings.Join(messages, "\n")
+	for _, expected := range []string{
+		"settings.json:2:18: expected customRanking[0] to be asc(attribute) or desc(attribute), got popularity",
+		"settings.json:3:24: min_word_size_for_1_typo (9) can't be greater than min_word_size_for_2_typos (8)",
+	} {
+		if !strings.Contains(got, expected) {
+			t.Errorf("Expected %q in lint errors, got:\n%s", expected, got)
+		}
+	}
+}
+
+// Lint rules are stricter than the resource, which keeps planning configurations that
+// predate them.
+func TestResourceIndex_lintRulesDontFailPlans(t *testing.T) {
+	meta, _ := newFakeClient()
+	_, err := testResourceApply(t, resourceIndex(), nil, map[string]interface{}{
+		"name":                     "products",
+		"custom_ranking":           []interface{}{"popularity"},
+		"min_word_size_for_1_typo": 9,
+	}, meta)
+	if err != nil {
+		t.Fatalf("Expected a configuration breaking lint rules to apply, got: %v", err)
+	}
+}
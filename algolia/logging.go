@@ -0,0 +1,154 @@
+package algolia
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/logging"
+)
+
+const redacted = "REDACTED"
+
+// Headers and JSON fields that carry API keys, including secured keys derived from them.
+var (
+	secretHeaders    = []string{"X-Algolia-API-Key"}
+	secretJSONFields = map[string]bool{"key": true, "value": true, "apiKey": true, "securedApiKey": true, "parentKey": true}
+)
+
+// Logs every request sent to Algolia and its response at TF_LOG=DEBUG, with API keys
+// redacted. Request bodies are logged as sent, so settings payloads show up exactly.
+type loggingTransport struct {
+	next http.RoundTripper
+}
+
+// Only wraps the transport when debug logging is on, as bodies have to be buffered to log them.
+func newLoggingTransport(next http.RoundTripper) http.RoundTripper {
+	if !logging.IsDebugOrHigher() {
+		return next
+	}
+	return &loggingTransport{next: next}
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	path := redactPath(req.URL.Path)
+
+	log.Printf("[DEBUG] Algolia request: %s %s\nHeaders:\n%s\nBody: %s",
+		req.Method, path, formatHeaders(req.Header), requestBody(req, path))
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	latency := time.Since(start)
+	if err != nil {
+		log.Printf("[DEBUG] Algolia request failed: %s %s after %s: %v", req.Method, path, latency, err)
+		return nil, err
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+	log.Printf("[DEBUG] Algolia response: %s %s: %d in %s\nBody: %s",
+		req.Method, path, resp.StatusCode, latency, redactBody(path, respBody))
+
+	return resp, nil
+}
+
+// A RoundTripper must not modify the request, so the body is read from a copy. Bodies
+// that can't be copied aren't logged rather than consumed.
+func requestBody(req *http.Request, path string) string {
+	if req.Body == nil || req.Body == http.NoBody {
+		return ""
+	}
+	if req.GetBody == nil {
+		return "(not logged)"
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return "(not logged)"
+	}
+	defer body.Close()
+	b, err := ioutil.ReadAll(body)
+	if err != nil {
+		return "(not logged)"
+	}
+	return redactBody(path, b)
+}
+
+func formatHeaders(h http.Header) string {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b bytes.Buffer
+	for _, name := range names {
+		value := strings.Join(h[name], ", ")
+		for _, secret := range secretHeaders {
+			if strings.EqualFold(name, secret) {
+				value = redacted
+			}
+		}
+		fmt.Fprintf(&b, "  %s: %s\n", name, value)
+	}
+	return b.String()
+}
+
+// Key endpoints carry the key itself in the path, e.g. /1/keys/<key>.
+func redactPath(path string) string {
+	parts := strings.Split(path, "/")
+	for i := 1; i < len(parts); i++ {
+		if parts[i-1] == "keys" && parts[i] != "" {
+			parts[i] = redacted
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// Redacts key values from bodies of key endpoints. Other bodies are logged untouched,
+// as they never carry keys and records may legitimately have fields named like them.
+func redactBody(path string, body []byte) string {
+	if !strings.Contains(path, "/keys") || len(body) == 0 {
+		return string(body)
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return redacted
+	}
+	b, err := json.Marshal(redactJSON(v))
+	if err != nil {
+		return redacted
+	}
+	return string(b)
+}
+
+func redactJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, field := range v {
+			if secretJSONFields[k] {
+				v[k] = redacted
+			} else {
+				v[k] = redactJSON(field)
+			}
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactJSON(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
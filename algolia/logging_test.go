@@ -0,0 +1,133 @@
+package algolia
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestLoggingTransport_redactsSecrets(t *testing.T) {
+	const secret = "0123456789abcdef0123456789abcdef"
+	secured := generateSecuredAPIKey(secret, "filters=user%3A42")
+
+	cases := []struct {
+		name     string
+		method   string
+		path     string
+		apiKey   string
+		reqBody  string
+		respBody string
+		secrets  []string
+		logged   []string
+	}{
+		{
+			name:     "api key header",
+			method:   "GET",
+			path:     "/1/indexes/products/settings",
+			apiKey:   secret,
+			respBody: `{"searchableAttributes":["title"]}`,
+			secrets:  []string{secret},
+			logged:   []string{"X-Algolia-Api-Key: " + redacted, `{"searchableAttributes":["title"]}`},
+		},
+		{
+			name:     "key lookup",
+			method:   "GET",
+			path:     "/1/keys/" + secret,
+			apiKey:   secret,
+			respBody: `{"value":"` + secret + `","acl":["search"]}`,
+			secrets:  []string{secret},
+			logged:   []string{"/1/keys/" + redacted, `"acl":["search"]`},
+		},
+		{
+			name:     "key listing",
+			method:   "GET",
+			path:     "/1/keys",
+			apiKey:   secret,
+			respBody: `{"keys":[{"value":"` + secret + `","acl":["search"]},{"value":"other-key","acl":["browse"]}]}`,
+			secrets:  []string{secret, "other-key"},
+			logged:   []string{`"acl":["browse"]`},
+		},
+		{
+			name:     "key creation",
+			method:   "POST",
+			path:     "/1/keys",
+			apiKey:   secret,
+			reqBody:  `{"acl":["search"],"description":"frontend"}`,
+			respBody: `{"key":"new-key","createdAt":"2018-01-01T00:00:00Z"}`,
+			secrets:  []string{secret, "new-key"},
+			logged:   []string{`"description":"frontend"`},
+		},
+		{
+			name:     "secured api key",
+			method:   "GET",
+			path:     "/1/keys/" + secured,
+			apiKey:   secured,
+			respBody: `{"securedApiKey":"` + secured + `","parentKey":"` + secret + `"}`,
+			secrets:  []string{secret, secured},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var sent string
+			transport := &loggingTransport{next: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				if req.Body != nil {
+					b, _ := ioutil.ReadAll(req.Body)
+					sent = string(b)
+				}
+				return &http.Response{
+					StatusCode: 200,
+					Body:       ioutil.NopCloser(strings.NewReader(c.respBody)),
+				}, nil
+			})}
+
+			var body *bytes.Reader
+			req, _ := http.NewRequest(c.method, "https://TESTAPP.algolia.net"+c.path, nil)
+			if c.reqBody != "" {
+				body = bytes.NewReader([]byte(c.reqBody))
+				req, _ = http.NewRequest(c.method, "https://TESTAPP.algolia.net"+c.path, body)
+			}
+			req.Header.Set("X-Algolia-API-Key", c.apiKey)
+			originalBody := req.Body
+
+			var resp *http.Response
+			var err error
+			output := testCaptureLog(func() {
+				resp, err = transport.RoundTrip(req)
+			})
+			if err != nil {
+				t.Fatalf("Error sending request: %v", err)
+			}
+
+			for _, s := range c.secrets {
+				if strings.Contains(output, s) {
+					t.Fatalf("Expected %q to be redacted, got log:\n%s", s, output)
+				}
+			}
+			for _, s := range c.logged {
+				if !strings.Contains(output, s) {
+					t.Fatalf("Expected log to contain %q, got:\n%s", s, output)
+				}
+			}
+
+			// The request goes out as it came in, and the response is still readable.
+			if req.Body != originalBody {
+				t.Fatal("Expected the request body not to be replaced")
+			}
+			if sent != c.reqBody {
+				t.Fatalf("Expected body %q to be sent, got %q", c.reqBody, sent)
+			}
+			if b, _ := ioutil.ReadAll(resp.Body); string(b) != c.respBody {
+				t.Fatalf("Expected response body %q, got %q", c.respBody, b)
+			}
+		})
+	}
+}
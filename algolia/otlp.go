@@ -0,0 +1,345 @@
+package algolia
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// The standard OTLP exporter variables. Only the http/json protocol is supported: the
+// official exporters encode with the generated protobuf types, which need a far newer grpc
+// than the one terraform plugins are built with, while OTLP/JSON is plain encoding/json.
+const (
+	otlpEndpointEnv       = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	otlpTracesEndpointEnv = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"
+	otlpHeadersEnv        = "OTEL_EXPORTER_OTLP_HEADERS"
+	otlpTracesHeadersEnv  = "OTEL_EXPORTER_OTLP_TRACES_HEADERS"
+	otlpProtocolEnv       = "OTEL_EXPORTER_OTLP_PROTOCOL"
+	otlpTracesProtocolEnv = "OTEL_EXPORTER_OTLP_TRACES_PROTOCOL"
+	otlpTimeoutEnv        = "OTEL_EXPORTER_OTLP_TIMEOUT"
+	otlpTracesTimeoutEnv  = "OTEL_EXPORTER_OTLP_TRACES_TIMEOUT"
+
+	otlpDefaultTimeout = 10 * time.Second
+)
+
+// Exports spans to an OTLP/HTTP collector as JSON.
+type otlpExporter struct {
+	endpoint string
+	headers  map[string]string
+	client   *http.Client
+}
+
+// Returns nil when no OTLP endpoint is configured. The signal specific variables win over
+// the generic ones, and a traces endpoint is used as is, while /v1/traces is appended to
+// the generic one, as the spec says.
+func newOTLPExporterFromEnv() (*otlpExporter, error) {
+	endpoint := os.Getenv(otlpTracesEndpointEnv)
+	if endpoint == "" {
+		base := os.Getenv(otlpEndpointEnv)
+		if base == "" {
+			return nil, nil
+		}
+		endpoint = strings.TrimSuffix(base, "/") + "/v1/traces"
+	}
+	if _, err := url.Parse(endpoint); err != nil {
+		return nil, fmt.Errorf("Invalid OTLP endpoint %q: %v", endpoint, err)
+	}
+
+	if protocol := otlpEnv(otlpTracesProtocolEnv, otlpProtocolEnv); protocol != "" && protocol != "http/json" {
+		return nil, fmt.Errorf("Unsupported OTLP protocol %q, only http/json is supported", protocol)
+	}
+
+	headers := map[string]string{}
+	for _, env := range []string{otlpHeadersEnv, otlpTracesHeadersEnv} {
+		parsed, err := parseOTLPHeaders(os.Getenv(env))
+		if err != nil {
+			return nil, fmt.Errorf("Invalid %s: %v", env, err)
+		}
+		for k, v := range parsed {
+			headers[k] = v
+		}
+	}
+
+	timeout := otlpDefaultTimeout
+	if v := otlpEnv(otlpTracesTimeoutEnv, otlpTimeoutEnv); v != "" {
+		ms, err := strconv.Atoi(v)
+		if err != nil || ms <= 0 {
+			return nil, fmt.Errorf("Invalid OTLP timeout %q, expected milliseconds", v)
+		}
+		timeout = time.Duration(ms) * time.Millisecond
+	}
+
+	return &otlpExporter{
+		endpoint: endpoint,
+		headers:  headers,
+		client:   &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func otlpEnv(names ...string) string {
+	for _, name := range names {
+		if v := strings.TrimSpace(os.Getenv(name)); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// Headers are a comma separated list of key=value pairs, with URL encoded values.
+func parseOTLPHeaders(s string) (map[string]string, error) {
+	headers := map[string]string{}
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, fmt.Errorf("expected key=value, got %q", pair)
+		}
+		value, err := url.QueryUnescape(strings.TrimSpace(kv[1]))
+		if err != nil {
+			return nil, err
+		}
+		headers[strings.TrimSpace(kv[0])] = value
+	}
+	return headers, nil
+}
+
+func (e *otlpExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	if len(spans) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(otlpTracesRequest(spans))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("Error exporting spans to %s: %v", e.endpoint, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("Error exporting spans to %s: %s: %s", e.endpoint, resp.Status, msg)
+	}
+	return nil
+}
+
+func (e *otlpExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// The OTLP/JSON mapping of ExportTraceServiceRequest. Trace and span ids are hex instead
+// of base64, 64 bit integers are strings and enums are numbers.
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string         `json:"stringValue,omitempty"`
+	BoolValue   *bool           `json:"boolValue,omitempty"`
+	IntValue    *string         `json:"intValue,omitempty"`
+	DoubleValue *float64        `json:"doubleValue,omitempty"`
+	ArrayValue  *otlpArrayValue `json:"arrayValue,omitempty"`
+}
+
+type otlpArrayValue struct {
+	Values []otlpAnyValue `json:"values"`
+}
+
+type otlpEvent struct {
+	TimeUnixNano string         `json:"timeUnixNano"`
+	Name         string         `json:"name"`
+	Attributes   []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Events            []otlpEvent    `json:"events,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpExportRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+// Groups spans by resource and instrumentation scope, in the order they came in.
+func otlpTracesRequest(spans []sdktrace.ReadOnlySpan) otlpExportRequest {
+	var req otlpExportRequest
+	resources := map[string]int{}
+	scopes := map[string]int{}
+
+	for _, s := range spans {
+		resourceKey := s.Resource().Encoded(attribute.DefaultEncoder())
+		r, ok := resources[resourceKey]
+		if !ok {
+			r = len(req.ResourceSpans)
+			resources[resourceKey] = r
+			req.ResourceSpans = append(req.ResourceSpans, otlpResourceSpans{
+				Resource: otlpResource{Attributes: otlpAttributes(s.Resource().Attributes())},
+			})
+		}
+
+		scope := s.InstrumentationScope()
+		scopeKey := resourceKey + "\x00" + scope.Name + "\x00" + scope.Version
+		i, ok := scopes[scopeKey]
+		if !ok {
+			i = len(req.ResourceSpans[r].ScopeSpans)
+			scopes[scopeKey] = i
+			req.ResourceSpans[r].ScopeSpans = append(req.ResourceSpans[r].ScopeSpans, otlpScopeSpans{
+				Scope: otlpScope{Name: scope.Name, Version: scope.Version},
+			})
+		}
+
+		req.ResourceSpans[r].ScopeSpans[i].Spans = append(req.ResourceSpans[r].ScopeSpans[i].Spans, otlpSpanFrom(s))
+	}
+	return req
+}
+
+func otlpSpanFrom(s sdktrace.ReadOnlySpan) otlpSpan {
+	sc := s.SpanContext()
+	traceID := sc.TraceID()
+	spanID := sc.SpanID()
+	span := otlpSpan{
+		TraceID:           hex.EncodeToString(traceID[:]),
+		SpanID:            hex.EncodeToString(spanID[:]),
+		Name:              s.Name(),
+		Kind:              int(s.SpanKind()),
+		StartTimeUnixNano: otlpTime(s.StartTime()),
+		EndTimeUnixNano:   otlpTime(s.EndTime()),
+		Attributes:        otlpAttributes(s.Attributes()),
+	}
+	if parent := s.Parent(); parent.HasSpanID() {
+		parentID := parent.SpanID()
+		span.ParentSpanID = hex.EncodeToString(parentID[:])
+	}
+	for _, e := range s.Events() {
+		span.Events = append(span.Events, otlpEvent{
+			TimeUnixNano: otlpTime(e.Time),
+			Name:         e.Name,
+			Attributes:   otlpAttributes(e.Attributes),
+		})
+	}
+
+	// The SDK numbers Error and Ok the other way around from OTLP.
+	status := s.Status()
+	switch status.Code {
+	case codes.Ok:
+		span.Status = otlpStatus{Code: 1}
+	case codes.Error:
+		span.Status = otlpStatus{Code: 2, Message: status.Description}
+	}
+	return span
+}
+
+func otlpTime(t time.Time) string {
+	if t.IsZero() {
+		return "0"
+	}
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+func otlpAttributes(attrs []attribute.KeyValue) []otlpKeyValue {
+	kvs := make([]otlpKeyValue, 0, len(attrs))
+	for _, kv := range attrs {
+		kvs = append(kvs, otlpKeyValue{Key: string(kv.Key), Value: otlpValue(kv.Value)})
+	}
+	return kvs
+}
+
+func otlpValue(v attribute.Value) otlpAnyValue {
+	switch v.Type() {
+	case attribute.BOOL:
+		b := v.AsBool()
+		return otlpAnyValue{BoolValue: &b}
+	case attribute.INT64:
+		i := strconv.FormatInt(v.AsInt64(), 10)
+		return otlpAnyValue{IntValue: &i}
+	case attribute.FLOAT64:
+		f := v.AsFloat64()
+		return otlpAnyValue{DoubleValue: &f}
+	case attribute.BOOLSLICE:
+		var values []otlpAnyValue
+		for _, b := range v.AsBoolSlice() {
+			values = append(values, otlpValue(attribute.BoolValue(b)))
+		}
+		return otlpAnyValue{ArrayValue: &otlpArrayValue{Values: values}}
+	case attribute.INT64SLICE:
+		var values []otlpAnyValue
+		for _, i := range v.AsInt64Slice() {
+			values = append(values, otlpValue(attribute.Int64Value(i)))
+		}
+		return otlpAnyValue{ArrayValue: &otlpArrayValue{Values: values}}
+	case attribute.FLOAT64SLICE:
+		var values []otlpAnyValue
+		for _, f := range v.AsFloat64Slice() {
+			values = append(values, otlpValue(attribute.Float64Value(f)))
+		}
+		return otlpAnyValue{ArrayValue: &otlpArrayValue{Values: values}}
+	case attribute.STRINGSLICE:
+		var values []otlpAnyValue
+		for _, s := range v.AsStringSlice() {
+			values = append(values, otlpValue(attribute.StringValue(s)))
+		}
+		return otlpAnyValue{ArrayValue: &otlpArrayValue{Values: values}}
+	default:
+		s := v.Emit()
+		return otlpAnyValue{StringValue: &s}
+	}
+}
@@ -0,0 +1,219 @@
+package algolia
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Sets environment variables, returning a function that puts the previous values back.
+func testSetenv(env map[string]string) func() {
+	old := map[string]*string{}
+	for k, v := range env {
+		if prev, ok := os.LookupEnv(k); ok {
+			old[k] = &prev
+		} else {
+			old[k] = nil
+		}
+		os.Setenv(k, v)
+	}
+	return func() {
+		for k, v := range old {
+			if v != nil {
+				os.Setenv(k, *v)
+			} else {
+				os.Unsetenv(k)
+			}
+		}
+	}
+}
+
+func TestOTLPExporter_export(t *testing.T) {
+	var path, auth, contentType string
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, auth, contentType = r.URL.Path, r.Header.Get("Authorization"), r.Header.Get("Content-Type")
+		b, _ := ioutil.ReadAll(r.Body)
+		if err := json.Unmarshal(b, &body); err != nil {
+			t.Errorf("Error decoding export request: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	defer testSetenv(map[string]string{
+		otlpEndpointEnv: server.URL + "/otlp/",
+		otlpHeadersEnv:  "Authorization=Bearer%20token,x-tenant=algolia",
+	})()
+	exporter, err := newTraceExporter()
+	if err != nil {
+		t.Fatalf("Error creating exporter: %v", err)
+	}
+	if _, ok := exporter.(*otlpExporter); !ok {
+		t.Fatalf("Expected an OTLP exporter, got %T", exporter)
+	}
+
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	ctx, parent := provider.Tracer(tracerName).Start(context.Background(), "algolia_index.create")
+	_, span := provider.Tracer(tracerName).Start(ctx, "HTTP PUT", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("algolia.index", "products"),
+		attribute.Int("http.status_code", 400),
+	))
+	endSpan(span, errors.New("Invalid settings"))
+
+	if path != "/otlp/v1/traces" {
+		t.Fatalf("Expected spans to be sent to /otlp/v1/traces, got %s", path)
+	}
+	if auth != "Bearer token" || contentType != "application/json" {
+		t.Fatalf("Expected headers to be sent, got Authorization %q and Content-Type %q", auth, contentType)
+	}
+
+	scope := body["resourceSpans"].([]interface{})[0].(map[string]interface{})["scopeSpans"].([]interface{})[0].(map[string]interface{})
+	if name := scope["scope"].(map[string]interface{})["name"]; name != tracerName {
+		t.Fatalf("Expected scope %s, got %v", tracerName, name)
+	}
+	got := scope["spans"].([]interface{})[0].(map[string]interface{})
+
+	parentID := parent.SpanContext().SpanID()
+	traceID := parent.SpanContext().TraceID()
+	expected := map[string]interface{}{
+		"name":         "HTTP PUT",
+		"kind":         float64(3),
+		"traceId":      traceID.String(),
+		"parentSpanId": parentID.String(),
+		"status":       map[string]interface{}{"code": float64(2), "message": "Invalid settings"},
+		"attributes": []interface{}{
+			map[string]interface{}{"key": "algolia.index", "value": map[string]interface{}{"stringValue": "products"}},
+			map[string]interface{}{"key": "http.status_code", "value": map[string]interface{}{"intValue": "400"}},
+		},
+	}
+	for k, v := range expected {
+		if b1, b2 := testJSON(t, v), testJSON(t, got[k]); b1 != b2 {
+			t.Fatalf("Expected %s to be %s, got %s", k, b1, b2)
+		}
+	}
+	if events := got["events"].([]interface{}); len(events) != 1 || events[0].(map[string]interface{})["name"] != "exception" {
+		t.Fatalf("Expected the error to be recorded as an exception event, got %v", events)
+	}
+}
+
+func testJSON(t *testing.T, v interface{}) string {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Error encoding %v: %v", v, err)
+	}
+	return string(b)
+}
+
+func TestOTLPExporter_env(t *testing.T) {
+	cases := []struct {
+		name     string
+		env      map[string]string
+		endpoint string
+		headers  map[string]string
+		err      bool
+	}{
+		{name: "not configured"},
+		{
+			name:     "generic endpoint",
+			env:      map[string]string{otlpEndpointEnv: "http://collector:4318"},
+			endpoint: "http://collector:4318/v1/traces",
+			headers:  map[string]string{},
+		},
+		{
+			name: "traces endpoint used as is",
+			env: map[string]string{
+				otlpEndpointEnv:       "http://collector:4318",
+				otlpTracesEndpointEnv: "http://traces:4318/custom",
+			},
+			endpoint: "http://traces:4318/custom",
+			headers:  map[string]string{},
+		},
+		{
+			name: "traces headers override generic ones",
+			env: map[string]string{
+				otlpEndpointEnv:      "http://collector:4318",
+				otlpHeadersEnv:       "a=1,b=2",
+				otlpTracesHeadersEnv: "b=3",
+			},
+			endpoint: "http://collector:4318/v1/traces",
+			headers:  map[string]string{"a": "1", "b": "3"},
+		},
+		{
+			name: "http/json protocol",
+			env: map[string]string{
+				otlpEndpointEnv: "http://collector:4318",
+				otlpProtocolEnv: "http/json",
+			},
+			endpoint: "http://collector:4318/v1/traces",
+			headers:  map[string]string{},
+		},
+		{
+			name: "grpc protocol",
+			env: map[string]string{
+				otlpEndpointEnv: "http://collector:4317",
+				otlpProtocolEnv: "grpc",
+			},
+			err: true,
+		},
+		{
+			name: "invalid headers",
+			env: map[string]string{
+				otlpEndpointEnv: "http://collector:4318",
+				otlpHeadersEnv:  "novalue",
+			},
+			err: true,
+		},
+		{
+			name: "invalid timeout",
+			env: map[string]string{
+				otlpEndpointEnv: "http://collector:4318",
+				otlpTimeoutEnv:  "10s",
+			},
+			err: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			clear := map[string]string{}
+			for _, k := range []string{otlpEndpointEnv, otlpTracesEndpointEnv, otlpHeadersEnv, otlpTracesHeadersEnv, otlpProtocolEnv, otlpTracesProtocolEnv, otlpTimeoutEnv, otlpTracesTimeoutEnv} {
+				clear[k] = ""
+			}
+			defer testSetenv(clear)()
+			defer testSetenv(c.env)()
+
+			exporter, err := newOTLPExporterFromEnv()
+			if c.err {
+				if err == nil {
+					t.Fatal("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Error creating exporter: %v", err)
+			}
+			if c.endpoint == "" {
+				if exporter != nil {
+					t.Fatalf("Expected no exporter, got %v", exporter)
+				}
+				return
+			}
+			if exporter.endpoint != c.endpoint {
+				t.Fatalf("Expected endpoint %s, got %s", c.endpoint, exporter.endpoint)
+			}
+			if testJSON(t, exporter.headers) != testJSON(t, c.headers) {
+				t.Fatalf("Expected headers %v, got %v", c.headers, exporter.headers)
+			}
+		})
+	}
+}
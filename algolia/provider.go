@@ -1,7 +1,10 @@
 package algolia
 
 import (
+	"fmt"
 	"log"
+	"path"
+	"time"
 
 	"github.com/hashicorp/terraform/helper/schema"
 )
@@ -19,20 +22,103 @@ func Provider() *schema.Provider {
 				Required:    true,
 				Description: "Algolia api key",
 			},
+			"index_prefix": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Prefix added to every index name sent to Algolia",
+			},
+			"index_suffix": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Suffix added to every index name sent to Algolia",
+			},
+			"read_only": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Refuse every call that would change the Algolia application",
+			},
+			"skip_credentials_validation": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Skip checking the application id and api key when the provider is configured, and the ACLs of the key before each call",
+			},
+			"max_concurrent_requests": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				Description:  "Maximum number of requests in flight to Algolia at once. 0 means unlimited",
+				ValidateFunc: IntGTE(0),
+			},
+			"requests_per_second": {
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				Default:      0.0,
+				Description:  "Maximum number of requests sent to Algolia per second. 0 means unlimited",
+				ValidateFunc: FloatGTE(0),
+			},
+			"list_indices_cache_ttl": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      300,
+				Description:  "Number of seconds the list of indices is cached for and shared between resources. 0 disables the cache",
+				ValidateFunc: IntGTE(0),
+			},
+			"allowed_index_patterns": {
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Optional:    true,
+				Description: "Glob patterns of the index names this provider may change. All indices are allowed when empty",
+			},
+			"protected_indices": {
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Optional:    true,
+				Description: "Glob patterns of index names that may never be deleted or have their settings reset",
+			},
 		},
 		ResourcesMap: map[string]*schema.Resource{
-			"algolia_index": resourceIndex(),
+			"algolia_index":          resourceIndex(),
+			"algolia_index_settings": resourceIndexSettings(),
+		},
+		DataSourcesMap: map[string]*schema.Resource{
+			"algolia_index":           dataSourceIndex(),
+			"algolia_indices":         dataSourceIndices(),
+			"algolia_secured_api_key": dataSourceSecuredAPIKey(),
 		},
 		ConfigureFunc: providerConfigure,
 	}
 }
 
 func providerConfigure(data *schema.ResourceData) (interface{}, error) {
+	initTracing()
+
 	config := Config{
 		ApplicationId: data.Get("application_id").(string),
 		ApiKey:        data.Get("api_key").(string),
+		IndexPrefix:   data.Get("index_prefix").(string),
+		IndexSuffix:   data.Get("index_suffix").(string),
+		ReadOnly:      data.Get("read_only").(bool),
+
+		MaxConcurrentRequests: data.Get("max_concurrent_requests").(int),
+		RequestsPerSecond:     data.Get("requests_per_second").(float64),
+		ListIndicesCacheTTL:   time.Duration(data.Get("list_indices_cache_ttl").(int)) * time.Second,
+
+		AllowedIndexPatterns: castStringList(data.Get("allowed_index_patterns").([]interface{})),
+		ProtectedIndices:     castStringList(data.Get("protected_indices").([]interface{})),
+	}
+
+	for _, pattern := range append(config.AllowedIndexPatterns, config.ProtectedIndices...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("Invalid index pattern %q: %v", pattern, err)
+		}
 	}
 
 	log.Println("[INFO] Initializing Algolia client")
-	return config.Client(), nil
+	client := config.Client()
+	if err := checkCredentials(client, config, data.Get("skip_credentials_validation").(bool)); err != nil {
+		return nil, err
+	}
+	return client, nil
 }
@@ -0,0 +1,85 @@
+package algolia
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/algolia/algoliasearch-client-go/algoliasearch"
+)
+
+// ACLs that allow a key to change anything in the application.
+var writeACLs = []string{"addObject", "deleteObject", "deleteIndex", "editSettings"}
+
+func errReadOnly(operation string) error {
+	return fmt.Errorf("Refusing to %s: the provider is configured with read_only = true", operation)
+}
+
+// Wraps the API so that every call that would change the application fails instead,
+// giving plan-only pipelines a guarantee that nothing is mutated.
+type readOnlyAPI struct {
+	AlgoliaAPI
+}
+
+func (a readOnlyAPI) CopyIndex(source, destination string) (res algoliasearch.UpdateTaskRes, err error) {
+	return res, errReadOnly(fmt.Sprintf("copy index %s to %s", source, destination))
+}
+
+func (a readOnlyAPI) MoveIndex(source, destination string) (res algoliasearch.UpdateTaskRes, err error) {
+	return res, errReadOnly(fmt.Sprintf("move index %s to %s", source, destination))
+}
+
+func (a readOnlyAPI) DeleteIndex(name string) (res algoliasearch.DeleteTaskRes, err error) {
+	return res, errReadOnly("delete index " + name)
+}
+
+func (a readOnlyAPI) ClearIndex(name string) (res algoliasearch.UpdateTaskRes, err error) {
+	return res, errReadOnly("clear index " + name)
+}
+
+func (a readOnlyAPI) AddObjects(name string, objects []algoliasearch.Object) (res algoliasearch.BatchRes, err error) {
+	return res, errReadOnly("save records to index " + name)
+}
+
+func (a readOnlyAPI) SetSettings(name string, settings algoliasearch.Map) (res algoliasearch.UpdateTaskRes, err error) {
+	return res, errReadOnly("set settings of index " + name)
+}
+
+func (a readOnlyAPI) BatchSynonyms(name string, synonyms []algoliasearch.Synonym, replaceExisting bool) (res algoliasearch.UpdateTaskRes, err error) {
+	return res, errReadOnly("save synonyms to index " + name)
+}
+
+func (a readOnlyAPI) BatchRules(name string, rules []algoliasearch.Rule, clearExisting bool) (res algoliasearch.BatchRulesRes, err error) {
+	return res, errReadOnly("save rules to index " + name)
+}
+
+// Looks up the ACL of the key to warn when it can write. Failing to look it up only warns
+// too, since the key may not be allowed to read its own ACL.
+func checkReadOnlyKey(api AlgoliaAPI, apiKey string) {
+	acl, admin, err := lookupKeyACL(api, apiKey)
+	if err != nil {
+		log.Printf("[WARN] read_only is set but the ACLs of api_key couldn't be checked: %v", err)
+		return
+	}
+	warnIfKeyCanWrite(acl, admin)
+}
+
+// A read-only provider should run with a search-only key, so nothing can be changed
+// even if a call slips past the wrapper. Only warns, as plans still work either way.
+func warnIfKeyCanWrite(acl []string, admin bool) {
+	if admin {
+		log.Printf("[WARN] read_only is set but api_key is the admin key, consider using a search-only key")
+		return
+	}
+
+	var granted []string
+	for _, a := range acl {
+		for _, write := range writeACLs {
+			if a == write {
+				granted = append(granted, a)
+			}
+		}
+	}
+	if len(granted) > 0 {
+		log.Printf("[WARN] read_only is set but api_key has write ACLs %v, consider using a search-only key", granted)
+	}
+}
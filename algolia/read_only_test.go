@@ -0,0 +1,53 @@
+package algolia
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/algolia/algoliasearch-client-go/algoliasearch"
+)
+
+// Captures what the provider logs while f runs.
+func testCaptureLog(f func()) string {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+	f()
+	return buf.String()
+}
+
+func TestCheckCredentials_readOnlyWarning(t *testing.T) {
+	cases := []struct {
+		name    string
+		acl     []string
+		skip    bool
+		warning bool
+	}{
+		{name: "write key", acl: []string{"search", "listIndexes", "settings", "editSettings"}, warning: true},
+		{name: "write key, validation skipped", acl: []string{"search", "listIndexes", "settings", "editSettings"}, skip: true, warning: true},
+		{name: "search key", acl: []string{"search", "listIndexes", "settings"}},
+		{name: "search key, validation skipped", acl: []string{"search", "listIndexes", "settings"}, skip: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			meta, api := newFakeClient()
+			api.keys["key"] = algoliasearch.Key{Value: "key", ACL: c.acl}
+			config := Config{ApplicationId: "TESTAPP", ApiKey: "key", ReadOnly: true}
+
+			var err error
+			output := testCaptureLog(func() {
+				err = checkCredentials(meta, config, c.skip)
+			})
+			if err != nil {
+				t.Fatalf("Error checking credentials: %v", err)
+			}
+			if warned := strings.Contains(output, "read_only is set but api_key has write ACLs [editSettings]"); warned != c.warning {
+				t.Fatalf("Expected a write ACL warning: %t, got log:\n%s", c.warning, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package algolia
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// Settings that change how records are indexed. Changing one of them makes Algolia rebuild
+// the whole index, every other setting only applies at query time.
+var indexingTimeAttributes = []string{
+	"allow_compression_of_integer_array",
+	"attributes_for_faceting",
+	"custom_ranking",
+	"searchable_attributes",
+	"separators_to_index",
+}
+
+// Returns the indexing-time settings changed by a plan, sorted by name.
+func changedIndexingTimeAttributes(d *schema.ResourceDiff) []string {
+	var changed []string
+	for _, attr := range indexingTimeAttributes {
+		if d.HasChange(attr) {
+			changed = append(changed, attr)
+		}
+	}
+	return changed
+}
+
+// Lists the settings of a plan that trigger a rebuild of an existing index in
+// reindex_changes, or fails the plan when block_reindex_changes is set. TF 0.11 has no plan
+// warnings, a computed attribute is what shows up in the plan output. It only holds the
+// settings, as the plan made at apply time has to match: the record count, which can
+// change in between, only goes to the log.
+func checkReindexImpact(d *schema.ResourceDiff, meta *AlgoliaClient) error {
+	// A new index has nothing to rebuild.
+	if d.Id() == "" {
+		return nil
+	}
+
+	changed := changedIndexingTimeAttributes(d)
+	if len(changed) == 0 {
+		return nil
+	}
+
+	fullName := meta.indexName(d.Id())
+	info, err := meta.findIndex(fullName)
+	if err != nil {
+		return fmt.Errorf("Error reading index %s: %v", fullName, err)
+	}
+	records := "an unknown number of"
+	if info != nil {
+		records = fmt.Sprintf("%d", info.Entries)
+	}
+
+	message := fmt.Sprintf("Changing %s rebuilds index %s and its %s records",
+		strings.Join(changed, ", "), fullName, records)
+	if block, _ := d.Get("block_reindex_changes").(bool); block {
+		return fmt.Errorf("%s, which block_reindex_changes forbids", message)
+	}
+
+	log.Printf("[WARN] %s", message)
+	return d.SetNew("reindex_changes", changed)
+}
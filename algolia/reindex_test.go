@@ -0,0 +1,85 @@
+package algolia
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func TestCheckReindexImpact(t *testing.T) {
+	resources := map[string]*schema.Resource{
+		"algolia_index":          resourceIndex(),
+		"algolia_index_settings": resourceIndexSettings(),
+	}
+
+	for name, r := range resources {
+		t.Run(name, func(t *testing.T) {
+			meta, api := newFakeClient()
+			api.addIndex("products", nil, 25)
+			config := func(overrides map[string]interface{}) map[string]interface{} {
+				raw := map[string]interface{}{
+					"name":           "products",
+					"custom_ranking": []interface{}{"desc(popularity)"},
+				}
+				if name == "algolia_index" {
+					raw["adopt_existing"] = true
+				}
+				for k, v := range overrides {
+					raw[k] = v
+				}
+				return raw
+			}
+
+			state, err := testResourceApply(t, r, nil, config(nil), meta)
+			if err != nil {
+				t.Fatalf("Error creating %s: %v", name, err)
+			}
+
+			// Query-time settings don't rebuild the index.
+			diff, err := r.Diff(state, testResourceConfig(t, config(map[string]interface{}{"hits_per_page": 50})), meta)
+			if err != nil {
+				t.Fatalf("Error planning: %v", err)
+			}
+			if _, ok := diff.Attributes["reindex_changes.#"]; ok {
+				t.Fatalf("Expected no reindex_changes for a query-time setting, got %v", diff.Attributes["reindex_changes.#"])
+			}
+
+			raw := config(map[string]interface{}{
+				"custom_ranking":          []interface{}{"desc(sales)"},
+				"attributes_for_faceting": []interface{}{"brand"},
+			})
+			diff, err = r.Diff(state, testResourceConfig(t, raw), meta)
+			if err != nil {
+				t.Fatalf("Error planning: %v", err)
+			}
+			var planned []string
+			for _, k := range []string{"reindex_changes.0", "reindex_changes.1"} {
+				if a, ok := diff.Attributes[k]; ok {
+					planned = append(planned, a.New)
+				}
+			}
+			if strings.Join(planned, ",") != "attributes_for_faceting,custom_ranking" {
+				t.Fatalf("Expected reindex_changes to list attributes_for_faceting and custom_ranking, got %v", planned)
+			}
+
+			state, err = r.Apply(state, diff, meta)
+			if err != nil {
+				t.Fatalf("Error applying: %v", err)
+			}
+			if n := state.Attributes["reindex_changes.#"]; n != "" && n != "0" {
+				t.Fatalf("Expected reindex_changes to be emptied by the apply, got %s", n)
+			}
+
+			raw = config(map[string]interface{}{
+				"custom_ranking":          []interface{}{"desc(margin)"},
+				"attributes_for_faceting": []interface{}{"brand"},
+				"block_reindex_changes":   true,
+			})
+			_, err = r.Diff(state, testResourceConfig(t, raw), meta)
+			if err == nil || !strings.Contains(err.Error(), "Changing custom_ranking rebuilds index products and its 25 records, which block_reindex_changes forbids") {
+				t.Fatalf("Expected block_reindex_changes to fail the plan, got %v", err)
+			}
+		})
+	}
+}
@@ -2,8 +2,10 @@ package algolia
 
 import (
 	"fmt"
+	"log"
 	"os"
 	"reflect"
+	"sort"
 
 	"github.com/algolia/algoliasearch-client-go/algoliasearch"
 	"github.com/hashicorp/terraform/helper/schema"
@@ -11,12 +13,28 @@ import (
 
 var rankingDefault = []string{"typo", "geo", "words", "filters", "proximity", "attribute", "exact", "custom"}
 
+// Attributes that control how Terraform manages the index rather than index settings.
+var indexLifecycleAttributes = []string{
+	"adopt_existing",
+	"force_destroy",
+	"destroy_behavior",
+	"backup_path",
+	"backup_records",
+	"restore_from",
+	"block_reindex_changes",
+	"rollout_strategy",
+}
+
 func resourceIndex() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceIndexCreate,
-		Read:   resourceIndexRead,
-		Update: resourceIndexUpdate,
-		Delete: resourceIndexDelete,
+		Create: traced("algolia_index", "create", resourceIndexCreate),
+		Read:   traced("algolia_index", "read", resourceIndexRead),
+		Update: traced("algolia_index", "update", resourceIndexUpdate),
+		Delete: traced("algolia_index", "delete", resourceIndexDelete),
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+		CustomizeDiff: resourceIndexCustomizeDiff,
 
 		Schema: map[string]*schema.Schema{
 			// Attributes
@@ -25,6 +43,11 @@ func resourceIndex() *schema.Resource {
 				Required:    true,
 				Description: "The name of this terraform index",
 			},
+			"full_name": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The name of the index in Algolia, including the provider index_prefix and index_suffix",
+			},
 			"searchable_attributes": &schema.Schema{
 				Type:        schema.TypeList,
 				Elem:        &schema.Schema{Type: schema.TypeString},
@@ -252,6 +275,98 @@ func resourceIndex() *schema.Resource {
 				Description:  "Selects a strategy to remove words from the query when it doesn’t match any hits.",
 				ValidateFunc: StringInSet([]string{"none", "lastWords", "firstWords", "allOptional"}),
 			},
+			"extra_settings_json": &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				Description:      "JSON object of settings not modelled by this resource, merged into the settings sent to Algolia.",
+				ValidateFunc:     validateExtraSettingsJSON,
+				DiffSuppressFunc: suppressEquivalentJSON,
+			},
+			"settings_fingerprint": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Hash of the settings terraform manages, as last applied or read.",
+			},
+			"updated_at": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Date the index was last updated, according to Algolia.",
+			},
+			"drift_report": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Settings changed outside of terraform since the last apply, as found by refreshes. Empty when nothing drifted.",
+			},
+			// Ownership
+			"settings_ownership": &schema.Schema{
+				Type:         schema.TypeString,
+				Default:      "authoritative",
+				Optional:     true,
+				Description:  "authoritative resets settings missing from config to their defaults. additive only writes and compares settings set to a non-default value in config.",
+				ValidateFunc: StringInSet([]string{"authoritative", "additive"}),
+			},
+			"managed_settings": &schema.Schema{
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Computed:    true,
+				Description: "Settings attributes set in config. In additive mode, the only ones terraform writes and compares.",
+			},
+			// Lifecycle
+			"reindex_changes": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Settings changed by the plan that make Algolia rebuild the index. Only set in plans, so the rebuild shows up in them, and emptied by the apply.",
+			},
+			"block_reindex_changes": &schema.Schema{
+				Type:        schema.TypeBool,
+				Default:     false,
+				Optional:    true,
+				Description: "Fail the plan instead of warning when a change requires Algolia to rebuild the index.",
+			},
+			"rollout_strategy": &schema.Schema{
+				Type:         schema.TypeString,
+				Default:      "in_place",
+				Optional:     true,
+				Description:  "How changes that rebuild the index are applied: in place, or on a shadow copy that is then moved over the live index. With shadow, records written to the index while the copy is rebuilt are lost when it is moved over the live index.",
+				ValidateFunc: StringInSet([]string{"in_place", "shadow"}),
+			},
+			"adopt_existing": &schema.Schema{
+				Type:        schema.TypeBool,
+				Default:     false,
+				Optional:    true,
+				Description: "Allow taking over an index that already exists instead of failing on create.",
+			},
+			"force_destroy": &schema.Schema{
+				Type:        schema.TypeBool,
+				Default:     false,
+				Optional:    true,
+				Description: "Allow destroying the index even if it has pending tasks, or deleting it even if it still contains records.",
+			},
+			"destroy_behavior": &schema.Schema{
+				Type:         schema.TypeString,
+				Default:      "delete",
+				Optional:     true,
+				Description:  "What to do with the live index on destroy: delete it, only clear its records, or detach it from state and leave it in place.",
+				ValidateFunc: StringInSet([]string{"delete", "clear_objects", "detach"}),
+			},
+			"backup_path": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Directory to back up settings, synonyms and rules to as NDJSON files before the index is deleted or replaced.",
+			},
+			"backup_records": &schema.Schema{
+				Type:        schema.TypeBool,
+				Default:     false,
+				Optional:    true,
+				Description: "Also back up all records of the index when backup_path is set.",
+			},
+			"restore_from": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Backup directory written by backup_path to replay into the index when it is created.",
+			},
 		},
 	}
 }
@@ -305,45 +420,167 @@ func buildSettingsFromResourceData(d *schema.ResourceData) algoliasearch.Setting
 }
 
 func readResourceFromSettings(d *schema.ResourceData, s algoliasearch.Settings) {
+	// In additive mode, only the attributes terraform wrote are compared with the live index.
+	var managed map[string]bool
+	if ownership, _ := d.Get("settings_ownership").(string); ownership == "additive" {
+		managed = map[string]bool{}
+		for _, attr := range castStringList(d.Get("managed_settings").([]interface{})) {
+			managed[attr] = true
+		}
+	}
+
+	for attr, v := range settingsAttributeValues(s) {
+		if managed == nil || managed[attr] {
+			d.Set(attr, v)
+		}
+	}
+}
+
+// Settings attribute values keyed by attribute name, in the shape they're stored in state.
+func settingsAttributeValues(s algoliasearch.Settings) map[string]interface{} {
 	ranking := s.Ranking
 	if reflect.DeepEqual(s.Ranking, rankingDefault) {
 		ranking = []string{}
 	}
 
-	d.Set("advanced_syntax", s.AdvancedSyntax)
-	d.Set("allow_compression_of_integer_array", s.AllowCompressionOfIntegerArray)
-	d.Set("allow_typos_on_numeric_tokens", s.AllowTyposOnNumericTokens)
-	d.Set("attribute_for_distinct", s.AttributeForDistinct)
-	d.Set("attributes_for_faceting", s.AttributesForFaceting)
-	d.Set("attributes_to_highlight", s.AttributesToHighlight)
-	d.Set("attributes_to_retrieve", s.AttributesToRetrieve)
-	d.Set("attributes_to_snippet", s.AttributesToSnippet)
-	d.Set("custom_ranking", s.CustomRanking)
-	d.Set("disable_typo_tolerance_on_attributes", s.DisableTypoToleranceOnAttributes)
-	d.Set("disable_typo_tolerance_on_words", s.DisableTypoToleranceOnWords)
-	d.Set("highlight_post_tag", s.HighlightPostTag)
-	d.Set("highlight_pre_tag", s.HighlightPreTag)
-	d.Set("hits_per_page", s.HitsPerPage)
-	d.Set("max_facet_hits", s.MaxFacetHits)
-	d.Set("max_values_per_facet", s.MaxValuesPerFacet)
-	d.Set("min_proximity", s.MinProximity)
-	d.Set("min_word_size_for_1_typo", s.MinWordSizefor1Typo)
-	d.Set("min_word_size_for_2_typos", s.MinWordSizefor2Typos)
-	d.Set("optional_words", s.OptionalWords)
-	d.Set("pagination_limited_to", s.PaginationLimitedTo)
-	d.Set("query_type", s.QueryType)
-	d.Set("ranking", ranking)
-	d.Set("remove_words_if_no_results", s.RemoveWordsIfNoResults)
-	d.Set("replace_synonyms_in_highlight", s.ReplaceSynonymsInHighlight)
-	d.Set("replicas", s.Replicas)
-	d.Set("response_fields", s.ResponseFields)
-	d.Set("restrict_highlight_and_snippet_arrays", s.RestrictHighlightAndSnippetArrays)
-	d.Set("searchable_attributes", s.SearchableAttributes)
-	d.Set("separators_to_index", s.SeparatorsToIndex)
-	d.Set("snippet_ellipsis_text", s.SnippetEllipsisText)
-	d.Set("sort_facets_values_by", s.SortFacetValuesBy)
-	d.Set("typo_tolerance", s.TypoTolerance)
-	d.Set("unretrievable_attributes", s.UnretrievableAttributes)
+	return map[string]interface{}{
+		"advanced_syntax":                       s.AdvancedSyntax,
+		"allow_compression_of_integer_array":    s.AllowCompressionOfIntegerArray,
+		"allow_typos_on_numeric_tokens":         s.AllowTyposOnNumericTokens,
+		"attribute_for_distinct":                s.AttributeForDistinct,
+		"attributes_for_faceting":               s.AttributesForFaceting,
+		"attributes_to_highlight":               s.AttributesToHighlight,
+		"attributes_to_retrieve":                s.AttributesToRetrieve,
+		"attributes_to_snippet":                 s.AttributesToSnippet,
+		"custom_ranking":                        s.CustomRanking,
+		"disable_typo_tolerance_on_attributes":  s.DisableTypoToleranceOnAttributes,
+		"disable_typo_tolerance_on_words":       s.DisableTypoToleranceOnWords,
+		"highlight_post_tag":                    s.HighlightPostTag,
+		"highlight_pre_tag":                     s.HighlightPreTag,
+		"hits_per_page":                         s.HitsPerPage,
+		"max_facet_hits":                        s.MaxFacetHits,
+		"max_values_per_facet":                  s.MaxValuesPerFacet,
+		"min_proximity":                         s.MinProximity,
+		"min_word_size_for_1_typo":              s.MinWordSizefor1Typo,
+		"min_word_size_for_2_typos":             s.MinWordSizefor2Typos,
+		"optional_words":                        s.OptionalWords,
+		"pagination_limited_to":                 s.PaginationLimitedTo,
+		"query_type":                            s.QueryType,
+		"ranking":                               ranking,
+		"remove_words_if_no_results":            s.RemoveWordsIfNoResults,
+		"replace_synonyms_in_highlight":         s.ReplaceSynonymsInHighlight,
+		"replicas":                              s.Replicas,
+		"response_fields":                       s.ResponseFields,
+		"restrict_highlight_and_snippet_arrays": s.RestrictHighlightAndSnippetArrays,
+		"searchable_attributes":                 s.SearchableAttributes,
+		"separators_to_index":                   s.SeparatorsToIndex,
+		"snippet_ellipsis_text":                 s.SnippetEllipsisText,
+		"sort_facet_values_by":                  s.SortFacetValuesBy,
+		"typo_tolerance":                        s.TypoTolerance,
+		"unretrievable_attributes":              s.UnretrievableAttributes,
+	}
+}
+
+func resourceIndexCustomizeDiff(d *schema.ResourceDiff, m interface{}) error {
+	return checkReindexImpact(d, m.(*AlgoliaClient))
+}
+
+// Maps each settings attribute of the schema to its key in the settings payload.
+var settingsAttributeKeys = map[string]string{
+	"advanced_syntax":                       "advancedSyntax",
+	"allow_compression_of_integer_array":    "allowCompressionOfIntegerArray",
+	"allow_typos_on_numeric_tokens":         "allowTyposOnNumericTokens",
+	"attribute_for_distinct":                "attributeForDistinct",
+	"attributes_for_faceting":               "attributesForFaceting",
+	"attributes_to_highlight":               "attributesToHighlight",
+	"attributes_to_retrieve":                "attributesToRetrieve",
+	"attributes_to_snippet":                 "attributesToSnippet",
+	"custom_ranking":                        "customRanking",
+	"disable_typo_tolerance_on_attributes":  "disableTypoToleranceOnAttributes",
+	"disable_typo_tolerance_on_words":       "disableTypoToleranceOnWords",
+	"highlight_post_tag":                    "highlightPostTag",
+	"highlight_pre_tag":                     "highlightPreTag",
+	"hits_per_page":                         "hitsPerPage",
+	"max_facet_hits":                        "maxFacetHits",
+	"max_values_per_facet":                  "maxValuesPerFacet",
+	"min_proximity":                         "minProximity",
+	"min_word_size_for_1_typo":              "minWordSizefor1Typo",
+	"min_word_size_for_2_typos":             "minWordSizefor2Typos",
+	"optional_words":                        "optionalWords",
+	"pagination_limited_to":                 "paginationLimitedTo",
+	"query_type":                            "queryType",
+	"ranking":                               "ranking",
+	"remove_words_if_no_results":            "removeWordsIfNoResults",
+	"replace_synonyms_in_highlight":         "replaceSynonymsInHighlight",
+	"replicas":                              "replicas",
+	"response_fields":                       "responseFields",
+	"restrict_highlight_and_snippet_arrays": "restrictHighlightAndSnippetArrays",
+	"searchable_attributes":                 "searchableAttributes",
+	"separators_to_index":                   "separatorsToIndex",
+	"snippet_ellipsis_text":                 "snippetEllipsisText",
+	"sort_facet_values_by":                  "sortFacetValuesBy",
+	"typo_tolerance":                        "typoTolerance",
+	"unretrievable_attributes":              "unretrievableAttributes",
+}
+
+// Returns the settings attributes set to something other than their default in config,
+// sorted by name. This is what additive ownership considers to be present in config.
+func configuredSettingsAttributes(d *schema.ResourceData) []string {
+	s := resourceIndex().Schema
+
+	var configured []string
+	for attr := range settingsAttributeKeys {
+		if !isDefaultSetting(s, attr, d.Get(attr)) {
+			configured = append(configured, attr)
+		}
+	}
+	sort.Strings(configured)
+	return configured
+}
+
+// Whether a settings attribute value is what the schema defaults it to. Lists default
+// to being empty.
+func isDefaultSetting(s map[string]*schema.Schema, attr string, v interface{}) bool {
+	switch v := v.(type) {
+	case []interface{}:
+		return len(castStringList(v)) == 0
+	case []string:
+		return len(v) == 0
+	default:
+		def := s[attr].Default
+		if def == nil {
+			def = s[attr].ZeroValue()
+		}
+		return v == def
+	}
+}
+
+// Returns the settings attributes that differ between state and config, sorted by name.
+func changedSettingsAttributes(d *schema.ResourceData) []string {
+	var changed []string
+	for attr := range settingsAttributeKeys {
+		if d.HasChange(attr) {
+			changed = append(changed, attr)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// Returns the items of a that are also in b, keeping the order of a.
+func intersectStrings(a, b []string) []string {
+	in := make(map[string]bool, len(b))
+	for _, v := range b {
+		in[v] = true
+	}
+
+	var out []string
+	for _, v := range a {
+		if in[v] {
+			out = append(out, v)
+		}
+	}
+	return out
 }
 
 // Takes an array of interface and casts to string
@@ -396,12 +633,16 @@ func settingsAsMap(s algoliasearch.Settings) algoliasearch.Map {
 		"minWordSizefor1Typo":        s.MinWordSizefor1Typo,
 		"minWordSizefor2Typos":       s.MinWordSizefor2Typos,
 		"optionalWords":              s.OptionalWords,
+		"paginationLimitedTo":        s.PaginationLimitedTo,
 		"queryType":                  s.QueryType,
 		"replaceSynonymsInHighlight": s.ReplaceSynonymsInHighlight,
 		"snippetEllipsisText":        s.SnippetEllipsisText,
 		"typoTolerance":              s.TypoTolerance,
 		"responseFields":             s.ResponseFields,
 		"removeWordsIfNoResults":     s.RemoveWordsIfNoResults,
+
+		"restrictHighlightAndSnippetArrays": s.RestrictHighlightAndSnippetArrays,
+		"sortFacetValuesBy":                 s.SortFacetValuesBy,
 	}
 
 	// Handle `Distinct` separately as it may be either a `bool` or a `float64`
@@ -468,46 +709,262 @@ func settingsAsMap(s algoliasearch.Settings) algoliasearch.Map {
 	return m
 }
 
-func resourceIndexCreate(d *schema.ResourceData, m interface{}) error {
-	client := *m.(*algoliasearch.Client)
-	index := client.InitIndex(d.Get("name").(string))
+// Same as settingsAsMap, but only emits the keys of the given settings attributes.
+func partialSettingsAsMap(s algoliasearch.Settings, attributes []string) algoliasearch.Map {
+	full := settingsAsMap(s)
+	m := algoliasearch.Map{}
+	for _, attr := range attributes {
+		key := settingsAttributeKeys[attr]
+		if v, ok := full[key]; ok {
+			m[key] = v
+		}
+	}
+	return m
+}
+
+// Builds the settings sent when terraform starts managing an index: every setting in
+// authoritative mode, only the configured ones in additive mode.
+func createSettingsPayload(d *schema.ResourceData) (algoliasearch.Map, error) {
 	settings := buildSettingsFromResourceData(d)
-	_, err := index.SetSettings(settingsAsMap(settings))
+	payload := settingsAsMap(settings)
+	configured := configuredSettingsAttributes(d)
+	if d.Get("settings_ownership").(string) == "additive" {
+		payload = partialSettingsAsMap(settings, configured)
+	}
+	d.Set("managed_settings", configured)
+
+	extra, err := parseExtraSettings(d.Get("extra_settings_json").(string))
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing extra_settings_json: %v", err)
+	}
+	return mergeExtraSettings(payload, extra), nil
+}
+
+func resourceIndexCreate(d *schema.ResourceData, m interface{}) error {
+	meta := m.(*AlgoliaClient)
+	name := d.Get("name").(string)
+	fullName := meta.indexName(name)
+	if err := meta.checkIndexAllowed(fullName); err != nil {
+		return err
+	}
+
+	// Setting settings on an existing index would silently take it over.
+	existing, err := meta.findIndex(fullName)
 	if err != nil {
-		return fmt.Errorf("Error creating index %s: %v", d.Get("name").(string), err)
+		return fmt.Errorf("Error checking whether index %s exists: %v", fullName, err)
+	}
+	if existing != nil {
+		if !d.Get("adopt_existing").(bool) {
+			return fmt.Errorf("Index %s already exists. Import it with `terraform import <address> %s`, or set adopt_existing = true to take it over", fullName, name)
+		}
+		// Adopting in authoritative mode resets every setting missing from config.
+		if d.Get("settings_ownership").(string) == "authoritative" {
+			if err := meta.checkIndexNotProtected(fullName, "reset the settings of"); err != nil {
+				return err
+			}
+		}
 	}
-	d.SetId(d.Get("name").(string))
+
+	payload, err := createSettingsPayload(d)
+	if err != nil {
+		return err
+	}
+	payload, err = meta.qualifyReplicas(payload)
+	if err != nil {
+		return err
+	}
+
+	// Restore first, so the configured settings are applied on top of the backed up ones.
+	if restoreFrom := d.Get("restore_from").(string); restoreFrom != "" {
+		if err := restoreIndex(meta.api, fullName, restoreFrom); err != nil {
+			return err
+		}
+	}
+
+	_, err = meta.api.SetSettings(fullName, payload)
+	meta.invalidateIndexCache()
+	if err != nil {
+		return fmt.Errorf("Error creating index %s: %v", fullName, err)
+	}
+	d.SetId(name)
+	d.Set("full_name", fullName)
+	recordAppliedSettings(d)
 	return nil
 }
 
 func resourceIndexRead(d *schema.ResourceData, m interface{}) error {
-	client := *m.(*algoliasearch.Client)
-	index := client.InitIndex(d.Id())
-	settings, err := index.GetSettings()
+	meta := m.(*AlgoliaClient)
+	fullName := meta.indexName(d.Id())
+
+	// Indices missing from the shared index list are gone, no need to ask for their settings.
+	info, err := meta.findIndex(fullName)
+	if err != nil {
+		return fmt.Errorf("Error reading index %s: %v", fullName, err)
+	}
+	if info == nil {
+		log.Printf("[WARN] Index %s not found, removing from state", fullName)
+		d.SetId("")
+		return nil
+	}
+
+	settings, err := meta.api.GetSettings(fullName)
 	if err != nil && err.Error() == "{\"message\":\"ObjectID does not exist\",\"status\":404}\n" {
 		d.SetId("")
 		return nil
 	}
 
+	before := managedSettingsPayload(d)
+
+	d.Set("name", d.Id())
+	d.Set("full_name", fullName)
+	d.Set("reindex_changes", []string{})
+	settings.Replicas = meta.logicalIndexNames(settings.Replicas)
 	readResourceFromSettings(d, settings)
+	if err := readExtraSettings(d, meta, fullName); err != nil {
+		return err
+	}
+
+	reportSettingsDrift(d, fullName, before, info.UpdatedAt)
 	return nil
 }
 
 func resourceIndexUpdate(d *schema.ResourceData, m interface{}) error {
-	client := *m.(*algoliasearch.Client)
-	index := client.InitIndex(d.Id())
+	meta := m.(*AlgoliaClient)
+	fullName := meta.indexName(d.Id())
+	if err := meta.checkIndexAllowed(fullName); err != nil {
+		return err
+	}
+	d.Set("reindex_changes", []string{})
+
+	// Only send what changed, so settings managed outside of terraform are left alone
+	// and unchanged indexing settings don't trigger a rebuild.
+	changed := changedSettingsAttributes(d)
+	configured := configuredSettingsAttributes(d)
+	authoritative := d.Get("settings_ownership").(string) == "authoritative"
+	if !authoritative {
+		// Settings dropped from config are no longer managed, but are left as they are.
+		changed = intersectStrings(changed, configured)
+	}
+	d.Set("managed_settings", configured)
+	if len(changed) == 0 && !d.HasChange("extra_settings_json") {
+		return nil
+	}
+
+	// In authoritative mode, settings dropped from config are reset to their defaults.
+	resets := authoritative && len(changed) > len(intersectStrings(changed, configured))
+
 	settings := buildSettingsFromResourceData(d)
-	_, err := index.SetSettings(settingsAsMap(settings))
+	payload := partialSettingsAsMap(settings, changed)
+	if d.HasChange("extra_settings_json") {
+		extra, err := changedExtraSettings(d)
+		if err != nil {
+			return fmt.Errorf("Error parsing extra_settings_json: %v", err)
+		}
+		for _, v := range extra {
+			// Extra settings dropped from config are sent as null, which resets them.
+			if v == nil {
+				resets = true
+			}
+		}
+		payload = mergeExtraSettings(payload, extra)
+	}
+
+	if resets {
+		if err := meta.checkIndexNotProtected(fullName, "reset the settings of"); err != nil {
+			return err
+		}
+	}
+	payload, err := meta.qualifyReplicas(payload)
 	if err != nil {
-		return fmt.Errorf("Error updating index %s: %v", d.Id(), err)
+		return err
+	}
+
+	// rollout_strategy only exists on algolia_index, algolia_index_settings updates in place.
+	if strategy, _ := d.Get("rollout_strategy").(string); strategy == "shadow" && len(intersectStrings(changed, indexingTimeAttributes)) > 0 {
+		if err := rolloutThroughShadow(meta, d.Id(), payload, castStringList(d.Get("replicas").([]interface{}))); err != nil {
+			return err
+		}
+	} else {
+		_, err := meta.api.SetSettings(fullName, payload)
+		if err != nil {
+			return fmt.Errorf("Error updating index %s: %v", fullName, err)
+		}
 	}
 
+	recordAppliedSettings(d)
 	return nil
 }
 
 func resourceIndexDelete(d *schema.ResourceData, m interface{}) error {
-	client := *m.(*algoliasearch.Client)
-	index := client.InitIndex(d.Get("name").(string))
-	_, err := index.Delete()
-	return err
+	meta := m.(*AlgoliaClient)
+	name := meta.indexName(d.Get("name").(string))
+	behavior := d.Get("destroy_behavior").(string)
+
+	if behavior == "detach" {
+		log.Printf("[INFO] Detaching index %s from state, the live index is left in place", name)
+		return nil
+	}
+
+	if err := meta.checkIndexAllowed(name); err != nil {
+		return err
+	}
+	action := "delete"
+	if behavior == "clear_objects" {
+		action = "clear"
+	}
+	if err := meta.checkIndexNotProtected(name, action); err != nil {
+		return err
+	}
+
+	if !d.Get("force_destroy").(bool) {
+		if err := checkIndexSafeToDestroy(meta, name, behavior); err != nil {
+			return err
+		}
+	}
+
+	if backupPath := d.Get("backup_path").(string); backupPath != "" {
+		if _, err := backupIndex(meta.api, name, backupPath, d.Get("backup_records").(bool)); err != nil {
+			return err
+		}
+	}
+
+	if behavior == "clear_objects" {
+		_, err := meta.api.ClearIndex(name)
+		if err != nil {
+			return fmt.Errorf("Error clearing index %s: %v", name, err)
+		}
+		return nil
+	}
+
+	_, err := meta.api.DeleteIndex(name)
+	meta.invalidateIndexCache()
+	if err != nil {
+		return fmt.Errorf("Error deleting index %s: %v", name, err)
+	}
+	return nil
+}
+
+// Refuses to destroy an index that has tasks in flight, or to delete one that still holds
+// records, since those can't be recovered once the index is gone. Clearing an index is
+// meant to drop its records, so only pending tasks block it. Set force_destroy to skip
+// this check.
+func checkIndexSafeToDestroy(meta *AlgoliaClient, name string, behavior string) error {
+	// Record counts have to be current for this check to mean anything.
+	meta.invalidateIndexCache()
+	index, err := meta.findIndex(name)
+	if err != nil {
+		return fmt.Errorf("Error checking index %s before destroy: %v", name, err)
+	}
+	if index == nil {
+		return nil
+	}
+
+	if behavior == "delete" && index.Entries > 0 {
+		return fmt.Errorf("Refusing to destroy index %s: it contains %d records. Set force_destroy = true to destroy it anyway", name, index.Entries)
+	}
+	if index.PendingTask || index.NumberOfPendingTasks > 0 {
+		return fmt.Errorf("Refusing to destroy index %s: it has pending tasks. Set force_destroy = true to destroy it anyway", name)
+	}
+
+	return nil
 }
@@ -0,0 +1,172 @@
+package algolia
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/algolia/algoliasearch-client-go/algoliasearch"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// Manages the settings of an index without owning the index itself, for indices
+// created outside of terraform. Destroying this resource never deletes the index.
+func resourceIndexSettings() *schema.Resource {
+	s := map[string]*schema.Schema{}
+	excluded := map[string]bool{}
+	for _, attr := range indexLifecycleAttributes {
+		excluded[attr] = true
+	}
+	for k, v := range resourceIndex().Schema {
+		if !excluded[k] {
+			s[k] = v
+		}
+	}
+
+	s["name"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Required:    true,
+		ForceNew:    true,
+		Description: "The name of the index whose settings are managed",
+	}
+	// The reindex check of plans applies to settings whoever owns the index.
+	s["block_reindex_changes"] = resourceIndex().Schema["block_reindex_changes"]
+	s["reset_on_destroy"] = &schema.Schema{
+		Type:        schema.TypeBool,
+		Default:     false,
+		Optional:    true,
+		Description: "Reset the index settings to their defaults on destroy instead of leaving them in place.",
+	}
+
+	return &schema.Resource{
+		Create: traced("algolia_index_settings", "create", resourceIndexSettingsCreate),
+		Read:   traced("algolia_index_settings", "read", resourceIndexRead),
+		Update: traced("algolia_index_settings", "update", resourceIndexUpdate),
+		Delete: traced("algolia_index_settings", "delete", resourceIndexSettingsDelete),
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+		CustomizeDiff: resourceIndexCustomizeDiff,
+
+		Schema: s,
+	}
+}
+
+func resourceIndexSettingsCreate(d *schema.ResourceData, m interface{}) error {
+	meta := m.(*AlgoliaClient)
+	name := d.Get("name").(string)
+	fullName := meta.indexName(name)
+	if err := meta.checkIndexAllowed(fullName); err != nil {
+		return err
+	}
+
+	// Algolia creates missing indices on the first settings change, which would leave a
+	// typo in name behind as an index this resource never deletes.
+	existing, err := meta.findIndex(fullName)
+	if err != nil {
+		return fmt.Errorf("Error checking whether index %s exists: %v", fullName, err)
+	}
+	if existing == nil {
+		return fmt.Errorf("Index %s does not exist. algolia_index_settings only manages the settings of existing indices, use algolia_index to create it", fullName)
+	}
+
+	// In authoritative mode, every setting missing from config is reset to its default.
+	if d.Get("settings_ownership").(string) == "authoritative" {
+		if err := meta.checkIndexNotProtected(fullName, "reset the settings of"); err != nil {
+			return err
+		}
+	}
+
+	payload, err := createSettingsPayload(d)
+	if err != nil {
+		return err
+	}
+	payload, err = meta.qualifyReplicas(payload)
+	if err != nil {
+		return err
+	}
+
+	_, err = meta.api.SetSettings(fullName, payload)
+	meta.invalidateIndexCache()
+	if err != nil {
+		return fmt.Errorf("Error setting settings of index %s: %v", fullName, err)
+	}
+	d.SetId(name)
+	d.Set("full_name", fullName)
+	recordAppliedSettings(d)
+	return nil
+}
+
+func resourceIndexSettingsDelete(d *schema.ResourceData, m interface{}) error {
+	meta := m.(*AlgoliaClient)
+	name := meta.indexName(d.Get("name").(string))
+
+	if !d.Get("reset_on_destroy").(bool) {
+		log.Printf("[INFO] Leaving settings of index %s in place", name)
+		return nil
+	}
+
+	if err := meta.checkIndexAllowed(name); err != nil {
+		return err
+	}
+	if err := meta.checkIndexNotProtected(name, "reset the settings of"); err != nil {
+		return err
+	}
+
+	payload, err := defaultSettingsPayload(d)
+	if err != nil {
+		return err
+	}
+
+	_, err = meta.api.SetSettings(name, payload)
+	if err != nil {
+		return fmt.Errorf("Error resetting settings of index %s: %v", name, err)
+	}
+	return nil
+}
+
+// Builds a settings payload that puts every managed setting back to its schema default.
+// In additive mode only the settings terraform wrote are reset. Replicas are only reset
+// when they were configured.
+func defaultSettingsPayload(d *schema.ResourceData) (algoliasearch.Map, error) {
+	s := resourceIndex().Schema
+
+	configured := map[string]bool{}
+	for _, attr := range castStringList(d.Get("managed_settings").([]interface{})) {
+		configured[attr] = true
+	}
+
+	attrs := make([]string, 0, len(settingsAttributeKeys))
+	if d.Get("settings_ownership").(string) == "additive" {
+		attrs = castStringList(d.Get("managed_settings").([]interface{}))
+	} else {
+		for attr := range settingsAttributeKeys {
+			attrs = append(attrs, attr)
+		}
+	}
+
+	payload := algoliasearch.Map{}
+	for _, attr := range attrs {
+		// Resetting replicas detaches them, which is only wanted for replicas this resource
+		// configured, not for those attached by whatever owns the index.
+		if attr == "replicas" && !configured["replicas"] {
+			continue
+		}
+		switch {
+		case s[attr].Type == schema.TypeList:
+			payload[settingsAttributeKeys[attr]] = []string{}
+		case s[attr].Default != nil:
+			payload[settingsAttributeKeys[attr]] = s[attr].Default
+		default:
+			payload[settingsAttributeKeys[attr]] = s[attr].ZeroValue()
+		}
+	}
+
+	extra, err := parseExtraSettings(d.Get("extra_settings_json").(string))
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing extra_settings_json: %v", err)
+	}
+	for k := range extra {
+		payload[k] = nil
+	}
+	return payload, nil
+}
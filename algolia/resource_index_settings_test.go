@@ -0,0 +1,76 @@
+package algolia
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestResourceIndexSettings_missingIndex(t *testing.T) {
+	meta, api := newFakeClient()
+
+	_, err := testResourceApply(t, resourceIndexSettings(), nil, map[string]interface{}{"name": "products"}, meta)
+	if err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Fatalf("Expected managing the settings of a missing index to fail, got: %v", err)
+	}
+	if api.hasIndex("products") {
+		t.Fatal("Expected no index to be created")
+	}
+}
+
+func TestResourceIndexSettings_resetOnDestroyReplicas(t *testing.T) {
+	cases := []struct {
+		name      string
+		ownership string
+		replicas  []interface{}
+		// Replicas attached after apply, as authoritative mode detaches unconfigured ones.
+		attached bool
+		expected interface{}
+	}{
+		// Replicas attached by the pipeline owning the index stay attached.
+		{name: "additive, not configured", ownership: "additive", expected: []interface{}{"products_by_price"}},
+		{name: "additive, configured", ownership: "additive", replicas: []interface{}{"products_by_price"}, expected: []string{}},
+		{name: "authoritative, not configured", ownership: "authoritative", attached: true, expected: []interface{}{"products_by_price"}},
+		{name: "authoritative, configured", ownership: "authoritative", replicas: []interface{}{"products_by_price"}, expected: []string{}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			meta, api := newFakeClient()
+			api.addIndex("products", map[string]interface{}{"replicas": []interface{}{"products_by_price"}}, 0)
+			r := resourceIndexSettings()
+
+			config := map[string]interface{}{
+				"name":                  "products",
+				"searchable_attributes": []interface{}{"title"},
+				"settings_ownership":    c.ownership,
+				"reset_on_destroy":      true,
+			}
+			if c.replicas != nil {
+				config["replicas"] = c.replicas
+			}
+			state, err := testResourceApply(t, r, nil, config, meta)
+			if err != nil {
+				t.Fatalf("Error creating index settings: %v", err)
+			}
+			if c.attached {
+				api.addIndex("products", map[string]interface{}{"replicas": []interface{}{"products_by_price"}}, 0)
+			}
+			state, err = r.Refresh(state, meta)
+			if err != nil {
+				t.Fatalf("Error reading index settings: %v", err)
+			}
+
+			if err := testResourceDestroy(r, state, meta); err != nil {
+				t.Fatalf("Error destroying index settings: %v", err)
+			}
+			settings := api.rawSettings("products")
+			if !reflect.DeepEqual(settings["replicas"], c.expected) {
+				t.Fatalf("Expected replicas %v after reset, got %v", c.expected, settings["replicas"])
+			}
+			if !reflect.DeepEqual(settings["searchableAttributes"], []string{}) {
+				t.Fatalf("Expected searchableAttributes to be reset, got %v", settings["searchableAttributes"])
+			}
+		})
+	}
+}
@@ -0,0 +1,453 @@
+package algolia
+
+import (
+	"net/http"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/algolia/algoliasearch-client-go/algoliasearch"
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func testResourceConfig(t *testing.T, raw map[string]interface{}) *terraform.ResourceConfig {
+	t.Helper()
+	c, err := config.NewRawConfig(raw)
+	if err != nil {
+		t.Fatalf("Error building config: %v", err)
+	}
+	return terraform.NewResourceConfig(c)
+}
+
+// Plans raw against state and applies the plan, going through Create or Update the same
+// way terraform apply does.
+func testResourceApply(t *testing.T, r *schema.Resource, state *terraform.InstanceState, raw map[string]interface{}, meta interface{}) (*terraform.InstanceState, error) {
+	t.Helper()
+	diff, err := r.Diff(state, testResourceConfig(t, raw), meta)
+	if err != nil || diff == nil {
+		return state, err
+	}
+	return r.Apply(state, diff, meta)
+}
+
+func testResourceDestroy(r *schema.Resource, state *terraform.InstanceState, meta interface{}) error {
+	_, err := r.Apply(state, &terraform.InstanceDiff{Destroy: true}, meta)
+	return err
+}
+
+// Returns a client replaying testdata/cassettes/<name>.json, or recording it against the
+// application from the environment with -record.
+func testCassetteClient(t *testing.T, name string) *AlgoliaClient {
+	t.Helper()
+	path := "testdata/cassettes/" + name + ".json"
+
+	config := Config{ApplicationId: "TESTAPP", ApiKey: "test-api-key"}
+	if *recordCassettes {
+		applicationId := os.Getenv("ALGOLIA_APPLICATION_ID")
+		apiKey := os.Getenv("ALGOLIA_API_KEY")
+		if applicationId == "" || apiKey == "" {
+			t.Fatal("ALGOLIA_APPLICATION_ID and ALGOLIA_API_KEY must be set to record cassettes")
+		}
+		config.transport = newCassetteTransport(http.DefaultTransport, path, false,
+			apiKey, config.ApiKey,
+			applicationId, config.ApplicationId,
+			strings.ToLower(applicationId), strings.ToLower(config.ApplicationId))
+		config.ApplicationId, config.ApiKey = applicationId, apiKey
+	} else {
+		config.transport = newCassetteTransport(nil, path, true)
+	}
+	return config.Client()
+}
+
+func TestResourceIndex_cassette(t *testing.T) {
+	meta := testCassetteClient(t, "resource_index")
+	r := resourceIndex()
+
+	state, err := testResourceApply(t, r, nil, map[string]interface{}{
+		"name":                  "tf_cassette",
+		"searchable_attributes": []interface{}{"title"},
+		"custom_ranking":        []interface{}{"desc(popularity)"},
+	}, meta)
+	if err != nil {
+		t.Fatalf("Error creating index: %v", err)
+	}
+	if state.ID != "tf_cassette" {
+		t.Fatalf("Expected ID tf_cassette, got %q", state.ID)
+	}
+
+	state, err = r.Refresh(state, meta)
+	if err != nil {
+		t.Fatalf("Error reading index: %v", err)
+	}
+	if got := state.Attributes["searchable_attributes.0"]; got != "title" {
+		t.Fatalf("Expected searchable_attributes.0 to be title, got %q", got)
+	}
+	if got := state.Attributes["updated_at"]; got == "" {
+		t.Fatal("Expected updated_at to be read from the index list")
+	}
+
+	state, err = testResourceApply(t, r, state, map[string]interface{}{
+		"name":                  "tf_cassette",
+		"searchable_attributes": []interface{}{"title", "body"},
+		"custom_ranking":        []interface{}{"desc(popularity)"},
+	}, meta)
+	if err != nil {
+		t.Fatalf("Error updating index: %v", err)
+	}
+
+	if err := testResourceDestroy(r, state, meta); err != nil {
+		t.Fatalf("Error destroying index: %v", err)
+	}
+}
+
+// A request that differs from the recorded one fails with the settings that differ.
+func TestResourceIndex_cassetteMismatch(t *testing.T) {
+	if *recordCassettes {
+		t.Skip("Only meaningful when replaying")
+	}
+	meta := testCassetteClient(t, "resource_index")
+
+	_, err := testResourceApply(t, resourceIndex(), nil, map[string]interface{}{
+		"name":                  "tf_cassette",
+		"searchable_attributes": []interface{}{"description"},
+		"custom_ranking":        []interface{}{"desc(popularity)"},
+	}, meta)
+	if err == nil {
+		t.Fatal("Expected creating an index with other settings than recorded to fail")
+	}
+	if !strings.Contains(err.Error(), `~ searchableAttributes: expected ["title"], got ["description"]`) {
+		t.Fatalf("Expected the error to point at searchableAttributes, got: %v", err)
+	}
+}
+
+func TestResourceIndex_crud(t *testing.T) {
+	meta, api := newFakeClient()
+	r := resourceIndex()
+
+	state, err := testResourceApply(t, r, nil, map[string]interface{}{
+		"name":                  "products",
+		"searchable_attributes": []interface{}{"title"},
+		"custom_ranking":        []interface{}{"desc(popularity)"},
+	}, meta)
+	if err != nil {
+		t.Fatalf("Error creating index: %v", err)
+	}
+	if state.ID != "products" {
+		t.Fatalf("Expected ID products, got %q", state.ID)
+	}
+	if !api.hasIndex("products") {
+		t.Fatal("Expected index products to be created")
+	}
+
+	// Changed outside of terraform, the next refresh picks it up.
+	api.addIndex("products", map[string]interface{}{"searchableAttributes": []interface{}{"description"}}, 0)
+	state, err = r.Refresh(state, meta)
+	if err != nil {
+		t.Fatalf("Error reading index: %v", err)
+	}
+	if got := state.Attributes["searchable_attributes.0"]; got != "description" {
+		t.Fatalf("Expected searchable_attributes.0 to be description, got %q", got)
+	}
+	if got := state.Attributes["custom_ranking.0"]; got != "desc(popularity)" {
+		t.Fatalf("Expected custom_ranking.0 to be desc(popularity), got %q", got)
+	}
+
+	state, err = testResourceApply(t, r, state, map[string]interface{}{
+		"name":                  "products",
+		"searchable_attributes": []interface{}{"title", "body"},
+		"custom_ranking":        []interface{}{"desc(popularity)"},
+	}, meta)
+	if err != nil {
+		t.Fatalf("Error updating index: %v", err)
+	}
+	payload := api.lastPayload("products")
+	if len(payload) != 1 || !reflect.DeepEqual(payload["searchableAttributes"], []string{"title", "body"}) {
+		t.Fatalf("Expected only searchableAttributes to be sent, got %v", payload)
+	}
+
+	if err := testResourceDestroy(r, state, meta); err != nil {
+		t.Fatalf("Error destroying index: %v", err)
+	}
+	if api.hasIndex("products") {
+		t.Fatal("Expected index products to be deleted")
+	}
+}
+
+func TestResourceIndex_readRemovedIndex(t *testing.T) {
+	meta, api := newFakeClient()
+	r := resourceIndex()
+
+	state, err := testResourceApply(t, r, nil, map[string]interface{}{"name": "products"}, meta)
+	if err != nil {
+		t.Fatalf("Error creating index: %v", err)
+	}
+	api.DeleteIndex("products")
+
+	state, err = r.Refresh(state, meta)
+	if err != nil {
+		t.Fatalf("Error reading index: %v", err)
+	}
+	if state != nil {
+		t.Fatalf("Expected an index deleted outside of terraform to be removed from state, got %v", state)
+	}
+}
+
+func TestResourceIndex_createExisting(t *testing.T) {
+	meta, api := newFakeClient()
+	api.addIndex("products", map[string]interface{}{"hitsPerPage": 50}, 0)
+
+	_, err := testResourceApply(t, resourceIndex(), nil, map[string]interface{}{"name": "products"}, meta)
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("Expected creating an existing index to fail, got: %v", err)
+	}
+	if got := api.rawSettings("products")["hitsPerPage"]; got != 50 {
+		t.Fatalf("Expected the existing settings to be left alone, got hitsPerPage %v", got)
+	}
+
+	_, err = testResourceApply(t, resourceIndex(), nil, map[string]interface{}{
+		"name":           "products",
+		"adopt_existing": true,
+	}, meta)
+	if err != nil {
+		t.Fatalf("Error adopting index: %v", err)
+	}
+}
+
+func TestResourceIndex_destroyGuards(t *testing.T) {
+	cases := []struct {
+		name    string
+		config  map[string]interface{}
+		records int
+		pending int
+		fails   bool
+		deleted bool
+		cleared bool
+	}{
+		{name: "delete with records", records: 3, fails: true},
+		{name: "force delete with records", config: map[string]interface{}{"force_destroy": true}, records: 3, deleted: true},
+		{name: "delete with pending tasks", pending: 1, fails: true},
+		{name: "force delete with pending tasks", config: map[string]interface{}{"force_destroy": true}, pending: 1, deleted: true},
+		{name: "clear with records", config: map[string]interface{}{"destroy_behavior": "clear_objects"}, records: 3, cleared: true},
+		{name: "clear with pending tasks", config: map[string]interface{}{"destroy_behavior": "clear_objects"}, records: 3, pending: 1, fails: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			meta, api := newFakeClient()
+			r := resourceIndex()
+
+			config := map[string]interface{}{"name": "products"}
+			for k, v := range c.config {
+				config[k] = v
+			}
+			state, err := testResourceApply(t, r, nil, config, meta)
+			if err != nil {
+				t.Fatalf("Error creating index: %v", err)
+			}
+			api.addIndex("products", nil, c.records)
+			api.setPendingTasks("products", c.pending)
+
+			err = testResourceDestroy(r, state, meta)
+			if c.fails != (err != nil) {
+				t.Fatalf("Expected destroy to fail: %t, got: %v", c.fails, err)
+			}
+			if c.deleted == api.hasIndex("products") {
+				t.Fatalf("Expected index products to be deleted: %t", c.deleted)
+			}
+			if cleared := api.hasIndex("products") && c.records > 0 && len(api.records("products")) == 0; c.cleared != cleared {
+				t.Fatalf("Expected index products to be cleared: %t", c.cleared)
+			}
+		})
+	}
+}
+
+func TestResourceIndex_protected(t *testing.T) {
+	meta, api := newFakeClient()
+	meta.protectedIndices = []string{"prod_*"}
+	api.addIndex("prod_products", map[string]interface{}{"hitsPerPage": 50}, 0)
+	r := resourceIndex()
+
+	_, err := testResourceApply(t, r, nil, map[string]interface{}{
+		"name":           "prod_products",
+		"adopt_existing": true,
+	}, meta)
+	if err == nil || !strings.Contains(err.Error(), "protected_indices") {
+		t.Fatalf("Expected adopting a protected index authoritatively to fail, got: %v", err)
+	}
+
+	state, err := testResourceApply(t, r, nil, map[string]interface{}{
+		"name":               "prod_products",
+		"adopt_existing":     true,
+		"settings_ownership": "additive",
+		"force_destroy":      true,
+	}, meta)
+	if err != nil {
+		t.Fatalf("Error adopting protected index additively: %v", err)
+	}
+	if err := testResourceDestroy(r, state, meta); err == nil {
+		t.Fatal("Expected destroying a protected index to fail")
+	}
+	if !api.hasIndex("prod_products") {
+		t.Fatal("Expected index prod_products to be left in place")
+	}
+}
+
+// A replica is changed by being attached, so it has to pass the same guardrails.
+func TestResourceIndex_replicaGuardrails(t *testing.T) {
+	cases := []struct {
+		name      string
+		allowed   []string
+		protected []string
+		expected  string
+	}{
+		{name: "disallowed", allowed: []string{"staging_*"}, expected: "does not match any of the allowed_index_patterns"},
+		{name: "protected", protected: []string{"prod_*"}, expected: "Refusing to attach as a replica index prod_products"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			meta, api := newFakeClient()
+			meta.allowedIndexPatterns = c.allowed
+			meta.protectedIndices = c.protected
+			r := resourceIndex()
+
+			_, err := testResourceApply(t, r, nil, map[string]interface{}{
+				"name":     "staging_products",
+				"replicas": []interface{}{"prod_products"},
+			}, meta)
+			if err == nil || !strings.Contains(err.Error(), c.expected) {
+				t.Fatalf("Expected creating an index with replica prod_products to fail with %q, got: %v", c.expected, err)
+			}
+			if api.hasIndex("staging_products") {
+				t.Fatal("Expected no index to be created")
+			}
+
+			state, err := testResourceApply(t, r, nil, map[string]interface{}{"name": "staging_products"}, meta)
+			if err != nil {
+				t.Fatalf("Error creating index: %v", err)
+			}
+			_, err = testResourceApply(t, r, state, map[string]interface{}{
+				"name":     "staging_products",
+				"replicas": []interface{}{"virtual(prod_products)"},
+			}, meta)
+			if err == nil || !strings.Contains(err.Error(), c.expected) {
+				t.Fatalf("Expected adding replica prod_products to fail with %q, got: %v", c.expected, err)
+			}
+			if replicas := api.rawSettings("staging_products")["replicas"]; len(toStringList(replicas)) > 0 {
+				t.Fatalf("Expected no replicas to be attached, got %v", replicas)
+			}
+		})
+	}
+}
+
+func TestResourceIndex_readOnly(t *testing.T) {
+	meta, api := newFakeClient()
+	meta.api = readOnlyAPI{AlgoliaAPI: api}
+
+	_, err := testResourceApply(t, resourceIndex(), nil, map[string]interface{}{"name": "products"}, meta)
+	if err == nil || !strings.Contains(err.Error(), "read_only") {
+		t.Fatalf("Expected creating an index with read_only to fail, got: %v", err)
+	}
+	if api.hasIndex("products") {
+		t.Fatal("Expected no index to be created with read_only")
+	}
+}
+
+// Replicas that didn't change are attached again after the shadow is moved over the live index.
+func TestResourceIndex_shadowRolloutKeepsReplicas(t *testing.T) {
+	meta, api := newFakeClient()
+	r := resourceIndex()
+
+	config := map[string]interface{}{
+		"name":                  "products",
+		"searchable_attributes": []interface{}{"title"},
+		"replicas":              []interface{}{"products_by_price"},
+		"rollout_strategy":      "shadow",
+	}
+	state, err := testResourceApply(t, r, nil, config, meta)
+	if err != nil {
+		t.Fatalf("Error creating index: %v", err)
+	}
+
+	config["searchable_attributes"] = []interface{}{"title", "body"}
+	if _, err := testResourceApply(t, r, state, config, meta); err != nil {
+		t.Fatalf("Error updating index: %v", err)
+	}
+
+	settings := api.rawSettings("products")
+	if !reflect.DeepEqual(settings["searchableAttributes"], []string{"title", "body"}) {
+		t.Fatalf("Expected searchableAttributes to be rolled out, got %v", settings["searchableAttributes"])
+	}
+	if !reflect.DeepEqual(settings["replicas"], []string{"products_by_price"}) {
+		t.Fatalf("Expected replicas to be attached again, got %v", settings["replicas"])
+	}
+	if api.hasIndex(meta.shadowIndexName("products")) {
+		t.Fatal("Expected the shadow index to be gone after the move")
+	}
+}
+
+func driftReport(state *terraform.InstanceState) []string {
+	n, _ := strconv.Atoi(state.Attributes["drift_report.#"])
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = state.Attributes["drift_report."+strconv.Itoa(i)]
+	}
+	return keys
+}
+
+func TestResourceIndex_driftReport(t *testing.T) {
+	meta, api := newFakeClient()
+	r := resourceIndex()
+	raw := map[string]interface{}{
+		"name":                  "products",
+		"searchable_attributes": []interface{}{"title"},
+	}
+
+	state, err := testResourceApply(t, r, nil, raw, meta)
+	if err != nil {
+		t.Fatalf("Error creating index: %v", err)
+	}
+	state, err = r.Refresh(state, meta)
+	if err != nil {
+		t.Fatalf("Error reading index: %v", err)
+	}
+	if got := driftReport(state); len(got) != 0 {
+		t.Fatalf("Expected no drift right after creating the index, got %v", got)
+	}
+
+	// Changed outside of terraform, across two refreshes.
+	api.SetSettings("products", algoliasearch.Map{"searchableAttributes": []string{"body"}, "hitsPerPage": 50})
+	state, err = r.Refresh(state, meta)
+	if err != nil {
+		t.Fatalf("Error reading index: %v", err)
+	}
+	if got, expected := driftReport(state), []string{"hitsPerPage", "searchableAttributes"}; !reflect.DeepEqual(got, expected) {
+		t.Fatalf("Expected drift_report %v, got %v", expected, got)
+	}
+
+	api.SetSettings("products", algoliasearch.Map{"customRanking": []string{"desc(popularity)"}})
+	state, err = r.Refresh(state, meta)
+	if err != nil {
+		t.Fatalf("Error reading index: %v", err)
+	}
+	if got, expected := driftReport(state), []string{"customRanking", "hitsPerPage", "searchableAttributes"}; !reflect.DeepEqual(got, expected) {
+		t.Fatalf("Expected drift_report %v, got %v", expected, got)
+	}
+
+	// Applying puts the settings back, which clears the report.
+	state, err = testResourceApply(t, r, state, raw, meta)
+	if err != nil {
+		t.Fatalf("Error updating index: %v", err)
+	}
+	state, err = r.Refresh(state, meta)
+	if err != nil {
+		t.Fatalf("Error reading index: %v", err)
+	}
+	if got := driftReport(state); len(got) != 0 {
+		t.Fatalf("Expected drift_report to be cleared by apply, got %v", got)
+	}
+}
@@ -0,0 +1,94 @@
+package algolia
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/algolia/algoliasearch-client-go/algoliasearch"
+)
+
+// Name of the temporary index a shadow rollout builds settings on. It keeps the provider
+// prefix and suffix, so it matches the same allowed_index_patterns as the live index.
+func (c *AlgoliaClient) shadowIndexName(name string) string {
+	return c.indexName(name + "_tf_shadow")
+}
+
+// Applies a settings payload without live traffic ever seeing a half-built index: the index
+// is copied to a shadow index, the settings are applied and rebuilt there, and the shadow is
+// moved over the live name in one step. The configured replicas are attached to the live
+// index again afterwards, whether they changed or not, since a copy doesn't carry them and
+// the moved index replaces the live one along with its replicas.
+//
+// Records written to the live index while the shadow is being built are lost by the move.
+func rolloutThroughShadow(meta *AlgoliaClient, name string, payload algoliasearch.Map, replicas []string) (err error) {
+	fullName := meta.indexName(name)
+	shadowName := meta.shadowIndexName(name)
+	if err := meta.checkIndexAllowed(shadowName); err != nil {
+		return err
+	}
+	if err := meta.checkIndexNotProtected(fullName, "replace"); err != nil {
+		return err
+	}
+	replicasPayload, err := meta.qualifyReplicas(algoliasearch.Map{"replicas": replicas})
+	if err != nil {
+		return err
+	}
+	defer meta.invalidateIndexCache()
+
+	shadowPayload := algoliasearch.Map{}
+	for k, v := range payload {
+		if k != "replicas" {
+			shadowPayload[k] = v
+		}
+	}
+
+	log.Printf("[INFO] Rolling out settings of index %s through %s", fullName, shadowName)
+	res, err := meta.api.CopyIndex(fullName, shadowName)
+	if err != nil {
+		return fmt.Errorf("Error copying index %s to %s: %v", fullName, shadowName, err)
+	}
+
+	// Until the move is done, a failure leaves the live index untouched and only the shadow
+	// needs cleaning up. Deleting it is harmless if a failed wait hid a move that went through.
+	moved := false
+	defer func() {
+		if err != nil && !moved {
+			if _, derr := meta.api.DeleteIndex(shadowName); derr != nil {
+				log.Printf("[WARN] Error deleting shadow index %s: %v", shadowName, derr)
+			}
+		}
+	}()
+
+	if err := meta.api.WaitTask(shadowName, res.TaskID); err != nil {
+		return fmt.Errorf("Error waiting for copy of index %s to %s: %v", fullName, shadowName, err)
+	}
+	if err := buildShadowIndex(meta, shadowName, shadowPayload); err != nil {
+		return err
+	}
+
+	res, err = meta.api.MoveIndex(shadowName, fullName)
+	if err != nil {
+		return fmt.Errorf("Error moving index %s to %s: %v", shadowName, fullName, err)
+	}
+	if err := meta.api.WaitTask(fullName, res.TaskID); err != nil {
+		return fmt.Errorf("Error waiting for move of index %s to %s: %v", shadowName, fullName, err)
+	}
+	moved = true
+
+	_, err = meta.api.SetSettings(fullName, replicasPayload)
+	if err != nil {
+		return fmt.Errorf("Error updating replicas of index %s: %v", fullName, err)
+	}
+	return nil
+}
+
+func buildShadowIndex(meta *AlgoliaClient, shadowName string, payload algoliasearch.Map) error {
+	res, err := meta.api.SetSettings(shadowName, payload)
+	if err != nil {
+		return fmt.Errorf("Error updating shadow index %s: %v", shadowName, err)
+	}
+	if err := meta.api.WaitTask(shadowName, res.TaskID); err != nil {
+		return fmt.Errorf("Error waiting for rebuild of shadow index %s: %v", shadowName, err)
+	}
+	return nil
+}
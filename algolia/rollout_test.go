@@ -0,0 +1,88 @@
+package algolia
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/algolia/algoliasearch-client-go/algoliasearch"
+)
+
+// Fails the calls of one method on one index, to test how failures halfway are handled.
+type failingAPI struct {
+	AlgoliaAPI
+	method string
+	index  string
+}
+
+func (a failingAPI) fails(method, index string) error {
+	if method == a.method && index == a.index {
+		return fmt.Errorf("{\"message\":\"%s failed\",\"status\":500}\n", method)
+	}
+	return nil
+}
+
+func (a failingAPI) MoveIndex(source, destination string) (algoliasearch.UpdateTaskRes, error) {
+	if err := a.fails("MoveIndex", source); err != nil {
+		return algoliasearch.UpdateTaskRes{}, err
+	}
+	return a.AlgoliaAPI.MoveIndex(source, destination)
+}
+
+func (a failingAPI) SetSettings(name string, settings algoliasearch.Map) (algoliasearch.UpdateTaskRes, error) {
+	if err := a.fails("SetSettings", name); err != nil {
+		return algoliasearch.UpdateTaskRes{}, err
+	}
+	return a.AlgoliaAPI.SetSettings(name, settings)
+}
+
+func (a failingAPI) WaitTask(name string, taskID int) error {
+	if err := a.fails("WaitTask", name); err != nil {
+		return err
+	}
+	return a.AlgoliaAPI.WaitTask(name, taskID)
+}
+
+func TestRolloutThroughShadow_failures(t *testing.T) {
+	cases := []struct {
+		method string
+		index  string
+	}{
+		{method: "WaitTask", index: "products_tf_shadow"},
+		{method: "SetSettings", index: "products_tf_shadow"},
+		{method: "MoveIndex", index: "products_tf_shadow"},
+		{method: "WaitTask", index: "products"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.method+" "+c.index, func(t *testing.T) {
+			meta, api := newFakeClient()
+			api.addIndex("products", map[string]interface{}{
+				"searchableAttributes": []interface{}{"title"},
+				"replicas":             []interface{}{"products_by_price"},
+			}, 10)
+			meta.api = failingAPI{AlgoliaAPI: api, method: c.method, index: c.index}
+
+			err := rolloutThroughShadow(meta, "products", algoliasearch.Map{"searchableAttributes": []string{"title", "body"}}, []string{"products_by_price"})
+			if err == nil || !strings.Contains(err.Error(), c.method+" failed") {
+				t.Fatalf("Expected the rollout to fail with the %s error, got %v", c.method, err)
+			}
+			if api.hasIndex("products_tf_shadow") {
+				t.Fatal("Expected the shadow index to be deleted")
+			}
+			if !api.hasIndex("products") {
+				t.Fatal("Expected the live index to be left in place")
+			}
+			if c.method != "WaitTask" || c.index != "products" {
+				settings := api.rawSettings("products")
+				if !reflect.DeepEqual(settings["searchableAttributes"], []interface{}{"title"}) {
+					t.Fatalf("Expected the live index to be untouched, got %v", settings["searchableAttributes"])
+				}
+				if len(api.records("products")) != 10 {
+					t.Fatalf("Expected the live index to keep its records, got %d", len(api.records("products")))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,5 @@
+resource "algolia_index" "index_2019-archive" {
+  name = "2019-archive"
+  hits_per_page = 50
+}
+
@@ -0,0 +1,12 @@
+resource "algolia_index" "products_price_asc" {
+  name = "products_price_asc"
+  ranking = ["asc(price)", "typo", "geo", "words", "filters", "proximity", "attribute", "exact", "custom"]
+}
+
+resource "algolia_index" "products" {
+  name = "products"
+  custom_ranking = ["desc(popularity)"]
+  replicas = ["products_price_asc"]
+  searchable_attributes = ["title", "unordered(description)"]
+}
+
@@ -0,0 +1,2 @@
+terraform import algolia_index.products_price_asc 'products_price_asc'
+terraform import algolia_index.products 'products'
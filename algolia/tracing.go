@@ -0,0 +1,171 @@
+package algolia
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/bpicolo/terraform-provider-algolia"
+
+// Tracing is off unless ALGOLIA_TRACE_FILE is set, which writes spans as JSON to a local file
+// that a collector can pick up, or an OTLP endpoint is set through the standard
+// OTEL_EXPORTER_OTLP_* variables, see otlp.go.
+const traceFileEnv = "ALGOLIA_TRACE_FILE"
+
+var (
+	initTracingOnce sync.Once
+	tracingEnabled  bool
+)
+
+func initTracing() {
+	initTracingOnce.Do(func() {
+		exporter, err := newTraceExporter()
+		if err != nil {
+			log.Printf("[WARN] Tracing disabled: %v", err)
+			return
+		}
+		if exporter == nil {
+			return
+		}
+
+		// Spans are exported as they end: terraform kills the plugin without a shutdown
+		// hook, so anything left in a batch would be lost.
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(
+			sdktrace.WithSyncer(exporter),
+			sdktrace.WithResource(resource.NewSchemaless(
+				attribute.String("service.name", "terraform-provider-algolia"),
+			)),
+		))
+		tracingEnabled = true
+	})
+}
+
+func newTraceExporter() (sdktrace.SpanExporter, error) {
+	if path := os.Getenv(traceFileEnv); path != "" {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("Error opening trace file %s: %v", path, err)
+		}
+		return stdouttrace.New(stdouttrace.WithWriter(f))
+	}
+
+	exporter, err := newOTLPExporterFromEnv()
+	if err != nil || exporter == nil {
+		return nil, err
+	}
+	return exporter, nil
+}
+
+func tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Wraps a CRUD function of a resource in a span. Without tracing configured this only
+// goes through the no-op tracer.
+func traced(resourceType string, operation string, f func(*schema.ResourceData, interface{}) error) func(*schema.ResourceData, interface{}) error {
+	return func(d *schema.ResourceData, m interface{}) error {
+		name, _ := d.Get("name").(string)
+		if name == "" {
+			name = d.Id()
+		}
+
+		_, span := tracer().Start(context.Background(), resourceType+"."+operation, trace.WithAttributes(
+			attribute.String("algolia.operation", operation),
+			attribute.String("algolia.index", name),
+		))
+		err := f(d, m)
+		endSpan(span, err)
+		return err
+	}
+}
+
+// Traces every HTTP call to Algolia. The client doesn't take a context, so these spans
+// can't be parented to the CRUD spans; they share the algolia.index attribute instead.
+type tracingTransport struct {
+	next http.RoundTripper
+}
+
+func newTracingTransport(next http.RoundTripper) http.RoundTripper {
+	if !tracingEnabled {
+		return next
+	}
+	return &tracingTransport{next: next}
+}
+
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	path := redactPath(req.URL.Path)
+	_, span := tracer().Start(req.Context(), "HTTP "+req.Method, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("http.method", req.Method),
+		attribute.String("http.host", req.URL.Host),
+		attribute.String("http.path", path),
+		attribute.String("algolia.operation", req.Method+" "+path),
+	))
+	if index := indexFromPath(req.URL.Path); index != "" {
+		span.SetAttributes(attribute.String("algolia.index", index))
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		endSpan(span, err)
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+	if resp.StatusCode >= 400 {
+		span.SetStatus(codes.Error, resp.Status)
+	}
+
+	// Writes answer with the task to wait for, which is what ties a call to its indexing work.
+	if req.Method != "GET" {
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			endSpan(span, err)
+			return nil, err
+		}
+		resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		var task struct {
+			TaskID int `json:"taskID"`
+		}
+		if json.Unmarshal(body, &task) == nil && task.TaskID != 0 {
+			span.SetAttributes(attribute.Int("algolia.task_id", task.TaskID))
+		}
+	}
+
+	endSpan(span, nil)
+	return resp, nil
+}
+
+// Index endpoints look like /1/indexes/<name>/...
+func indexFromPath(path string) string {
+	parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	if len(parts) >= 3 && parts[0] == "1" && parts[1] == "indexes" && parts[2] != "*" {
+		return parts[2]
+	}
+	return ""
+}
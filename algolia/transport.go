@@ -0,0 +1,82 @@
+package algolia
+
+import (
+	"io"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Limits how many requests are in flight and how fast they are sent. A single transport is
+// shared by every call the provider makes, task polling included, so the limits hold across
+// all the resources terraform runs in parallel.
+type throttledTransport struct {
+	next   http.RoundTripper
+	sem    chan struct{}
+	tokens chan struct{}
+}
+
+// A zero maxConcurrent or requestsPerSecond leaves that limit off.
+func newThrottledTransport(next http.RoundTripper, maxConcurrent int, requestsPerSecond float64) http.RoundTripper {
+	if maxConcurrent <= 0 && requestsPerSecond <= 0 {
+		return next
+	}
+
+	t := &throttledTransport{next: next}
+	if maxConcurrent > 0 {
+		t.sem = make(chan struct{}, maxConcurrent)
+	}
+	if requestsPerSecond > 0 {
+		// Token bucket holding up to a second worth of requests, refilled one token at a time.
+		burst := int(math.Ceil(requestsPerSecond))
+		t.tokens = make(chan struct{}, burst)
+		for i := 0; i < burst; i++ {
+			t.tokens <- struct{}{}
+		}
+		go t.refill(time.Duration(float64(time.Second) / requestsPerSecond))
+	}
+	return t
+}
+
+func (t *throttledTransport) refill(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	for range ticker.C {
+		select {
+		case t.tokens <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (t *throttledTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.tokens != nil {
+		<-t.tokens
+	}
+	if t.sem == nil {
+		return t.next.RoundTrip(req)
+	}
+
+	t.sem <- struct{}{}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		<-t.sem
+		return nil, err
+	}
+
+	// The request is in flight until its body has been read and closed.
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: func() { <-t.sem }}
+	return resp, nil
+}
+
+type releasingBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
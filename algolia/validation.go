@@ -2,6 +2,7 @@ package algolia
 
 import (
 	"fmt"
+	"regexp"
 
 	"github.com/hashicorp/terraform/helper/schema"
 )
@@ -54,7 +55,54 @@ func StringInSet(set []string) schema.SchemaValidateFunc {
 				return
 			}
 		}
-		es = append(es, fmt.Errorf("expected %s to be in the set %v, got %d", k, set, v))
+		es = append(es, fmt.Errorf("expected %s to be in the set %v, got %s", k, set, v))
+		return
+	}
+}
+
+func StringIsValidRegexp() schema.SchemaValidateFunc {
+	return func(i interface{}, k string) (s []string, es []error) {
+		v, ok := i.(string)
+		if !ok {
+			es = append(es, fmt.Errorf("expected type of %s to be string", k))
+			return
+		}
+
+		if _, err := regexp.Compile(v); err != nil {
+			es = append(es, fmt.Errorf("expected %s to be a valid regular expression, got %v", k, err))
+		}
+		return
+	}
+}
+
+func FloatGTE(min float64) schema.SchemaValidateFunc {
+	return func(i interface{}, k string) (s []string, es []error) {
+		v, ok := i.(float64)
+		if !ok {
+			es = append(es, fmt.Errorf("expected type of %s to be float", k))
+			return
+		}
+
+		if v < min {
+			es = append(es, fmt.Errorf("expected %s to be greater than or equal to %v, got %v", k, min, v))
+			return
+		}
+
+		return
+	}
+}
+
+func StringMatches(re *regexp.Regexp, description string) schema.SchemaValidateFunc {
+	return func(i interface{}, k string) (s []string, es []error) {
+		v, ok := i.(string)
+		if !ok {
+			es = append(es, fmt.Errorf("expected type of %s to be string", k))
+			return
+		}
+
+		if !re.MatchString(v) {
+			es = append(es, fmt.Errorf("expected %s to be %s, got %s", k, description, v))
+		}
 		return
 	}
 }
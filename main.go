@@ -1,15 +1,110 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"os"
+
 	"github.com/bpicolo/terraform-provider-algolia/algolia"
 	"github.com/hashicorp/terraform/plugin"
 	"github.com/hashicorp/terraform/terraform"
 )
 
 func main() {
+	// Terraform always starts plugins with its magic cookie set, so subcommands are only
+	// available when the binary is run by hand.
+	if os.Getenv("TF_PLUGIN_MAGIC_COOKIE") == "" && len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "export":
+			os.Exit(export(os.Args[2:]))
+		case "lint":
+			os.Exit(lint(os.Args[2:]))
+		}
+	}
+
 	plugin.Serve(&plugin.ServeOpts{
 		ProviderFunc: func() terraform.ResourceProvider {
 			return algolia.Provider()
 		},
 	})
 }
+
+func export(args []string) int {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	applicationId := flags.String("application-id", os.Getenv("ALGOLIA_APPLICATION_ID"), "Algolia application id")
+	apiKey := flags.String("api-key", os.Getenv("ALGOLIA_API_KEY"), "Algolia api key")
+	indexPrefix := flags.String("index-prefix", "", "index_prefix the provider is configured with")
+	indexSuffix := flags.String("index-suffix", "", "index_suffix the provider is configured with")
+	filter := flags.String("filter", "", "Only export indices whose name starts with this prefix")
+	out := flags.String("out", "", "File to write the configuration to instead of stdout")
+	importsOut := flags.String("imports", "", "File to write the terraform import commands to instead of stderr")
+	flags.Parse(args)
+
+	if *applicationId == "" || *apiKey == "" {
+		fmt.Fprintln(os.Stderr, "export: -application-id and -api-key (or ALGOLIA_APPLICATION_ID and ALGOLIA_API_KEY) are required")
+		return 2
+	}
+
+	w := os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "export: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+
+	imports := os.Stderr
+	if *importsOut != "" {
+		f, err := os.Create(*importsOut)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "export: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		imports = f
+	}
+
+	config := algolia.Config{
+		ApplicationId: *applicationId,
+		ApiKey:        *apiKey,
+		IndexPrefix:   *indexPrefix,
+		IndexSuffix:   *indexSuffix,
+		ReadOnly:      true,
+	}
+	if err := algolia.Export(w, imports, config.Client(), *filter); err != nil {
+		fmt.Fprintf(os.Stderr, "export: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func lint(args []string) int {
+	flags := flag.NewFlagSet("lint", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: lint FILE...")
+		fmt.Fprintln(os.Stderr, "Checks Algolia settings JSON files against the algolia_index schema.")
+	}
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	status := 0
+	for _, path := range flags.Args() {
+		errs, err := algolia.LintSettingsFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "lint: %v\n", err)
+			status = 1
+			continue
+		}
+		for _, e := range errs {
+			fmt.Println(e.Error())
+			status = 1
+		}
+	}
+	return status
+}